@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply [planFile]",
+	Short: "To make the calendar changes saved by 'mgint plan'",
+	Args: usageArgs(func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("requires a plan file created with 'mgint plan -o'")
+		}
+		return nil
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plan, err := handlers.ReadSyncPlan(args[0])
+		if err != nil {
+			return err
+		}
+
+		calendarClient, err := handlers.NewCalendarClient(googleClientID, googleSecret)
+		if err != nil {
+			return err
+		}
+
+		// refuse to apply a plan made against a calendar that has since changed
+		if err := calendarClient.CheckSyncPlanIsCurrent(plan); err != nil {
+			var staleErr *handlers.StalePlanError
+			if errors.As(err, &staleErr) {
+				return fmt.Errorf("refusing to apply %s, run 'mgint plan' again to make a new plan: %w", args[0], err)
+			}
+			return err
+		}
+
+		plan.Print(os.Stdout)
+
+		if err := calendarClient.CreatePlanCalendar(plan); err != nil {
+			return err
+		}
+
+		mondayClient := handlers.NewMondayClient(mondayAPIKey)
+		if err := mondayClient.ApplyItemFixes(plan); err != nil {
+			return err
+		}
+
+		// calendar edits are written to Monday.com before their events are recorded as synced
+		if err := mondayClient.ApplySyncPlan(plan); err != nil {
+			return err
+		}
+
+		if err := calendarClient.ApplySyncPlan(plan); err != nil {
+			return err
+		}
+
+		fmt.Println("\ndone applying plan to google calendar")
+		plan.PrintFailures(os.Stdout)
+		return plan.FailuresError()
+	},
+}
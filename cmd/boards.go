@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+	"github.com/spf13/cobra"
+)
+
+var boardsCmd = &cobra.Command{
+	Use:   "boards",
+	Short: "To find and manage Monday.com boards that can be synced",
+}
+
+var (
+	templateBoardID string
+	newBoardName    string
+)
+
+func init() {
+	boardsCreateCmd.Flags().StringVar(&templateBoardID, "template", "", "Monday.com boardID of the board to copy (required)")
+	boardsCreateCmd.Flags().StringVar(&newBoardName, "name", "", "name of the new board (default is '<template name> - Week of <date>')")
+	addWeekFlags(boardsCreateCmd)
+
+	boardsCmd.AddCommand(boardsListCmd)
+	boardsCmd.AddCommand(boardsCreateCmd)
+	boardsCmd.AddCommand(boardsColumnsCmd)
+
+	rootCmd.AddCommand(boardsCmd)
+}
+
+var boardsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list every board your Monday.com api key can see and whether it can be synced",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mondayClient := handlers.NewMondayClient(mondayAPIKey)
+
+		boards, err := mondayClient.ListBoards()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tWORKSPACE\tITEMS\tCOMPATIBLE\tMISSING")
+		for _, board := range boards {
+			workspace := "Main workspace"
+			if board.Workspace != nil {
+				workspace = board.Workspace.Name
+			}
+
+			compatible, missing := handlers.BoardCompatibility(&board)
+			compatibleString := "no"
+			if compatible {
+				compatibleString = "yes"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+				board.ID, board.Name, workspace, board.ItemsCount, compatibleString, strings.Join(missing, ", "))
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var boardsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a board for a week from a template board",
+	Long: "create a board for a week with a group for every day and the columns needed to sync it." +
+		" Items in the template's day groups are copied to the same weekday of the new week.",
+	Args: usageArgs(func(cmd *cobra.Command, args []string) error {
+		if templateBoardID == "" {
+			return errors.New("requires a template Monday.com boardID set with --template")
+		}
+
+		if err := boardIDArgValidation(templateBoardID); err != nil {
+			return fmt.Errorf("issue validating template boardID: %w", err)
+		}
+		return nil
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templateID, err := strconv.Atoi(templateBoardID)
+		if err != nil {
+			return err
+		}
+
+		opts, err := syncOptions()
+		if err != nil {
+			return err
+		}
+
+		weekOf := opts.WeekOf
+		if weekOf.IsZero() {
+			weekOf = time.Now()
+		}
+
+		// due dates are shown in the time zone of the Monday.com account
+		loc := time.Local
+		if opts.TimeZone != "" {
+			loc, err = time.LoadLocation(opts.TimeZone)
+			if err != nil {
+				return &usageError{fmt.Errorf("issue loading time zone '%s': %w", opts.TimeZone, err)}
+			}
+		}
+
+		mondayClient := handlers.NewMondayClient(mondayAPIKey)
+
+		board, err := mondayClient.CreateBoardFromTemplate(templateID, weekOf, newBoardName, loc)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("created board '%s', run 'mgint sync %s' to sync it\n", board.Name, board.ID)
+		return nil
+	},
+}
+
+var boardsColumnsCmd = &cobra.Command{
+	Use:   "columns [boardID]",
+	Short: "show the columns of a board and the ones used for due dates, estimates and the other roles",
+	Long: "show the columns of a board and which are used when syncing, picked from the columns mapping in the" +
+		" config file or detected by their title and type.",
+	Args: usageArgs(boardIDArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		boardID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+
+		mappings, err := columnMappings()
+		if err != nil {
+			return err
+		}
+
+		mondayClient := handlers.NewMondayClient(mondayAPIKey)
+		board, err := mondayClient.GetAllItemsInGroupsByBoardId(boardID)
+		if err != nil {
+			return err
+		}
+
+		if err := board.MapColumns(mappings[board.ID]); err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTITLE\tTYPE\tROLE\tFROM")
+		for _, column := range board.Columns {
+			role, source, ok := board.ColumnRoleOf(column.ID)
+			if !ok {
+				fmt.Fprintf(w, "%s\t%s\t%s\t\t\n", column.ID, column.Title, column.Type)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", column.ID, column.Title, column.Type, role, source)
+		}
+		w.Flush()
+
+		for _, role := range handlers.ColumnRoles {
+			if _, ok := board.ColumnMap[role]; ok {
+				continue
+			}
+			if role.ConfigOnly() {
+				fmt.Printf("no column mapped for %s, it is only used when mapped in the config file\n", role)
+				continue
+			}
+			fmt.Printf("no column found for %s\n", role)
+		}
+		return nil
+	},
+}
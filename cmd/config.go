@@ -29,7 +29,7 @@ func newCmdConfigSet() *cobra.Command {
 		Long:  "set one or all the config variables using '" + usageString + "'",
 		// allows us to ignore the required config flags set on the root cmd
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			return nil
+			return configErr
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return set(args)
@@ -76,13 +76,13 @@ func newCmdConfigSet() *cobra.Command {
 
 func set(args []string) error {
 	if len(args) < 1 {
-		return errors.New("no args given")
+		return &usageError{errors.New("no args given")}
 	}
 
 	for _, arg := range args {
 		keys := strings.Split(arg, "=")
 		if len(keys) < 2 || keys[0] == "" || keys[1] == "" {
-			return errors.New("use '<key>=<value>' format (no spaces)")
+			return &usageError{errors.New("use '<key>=<value>' format (no spaces)")}
 		}
 
 		flagExists := false
@@ -93,13 +93,13 @@ func set(args []string) error {
 				viper.Set(cF.Name, keys[1])
 				err := viper.WriteConfig()
 				if err != nil {
-					panic(err)
+					return fmt.Errorf("issue writing config file: %w", err)
 				}
 			}
 		}
 
 		if !flagExists {
-			return fmt.Errorf("the key '%s' is not a valid key in the config", keys[0])
+			return &usageError{fmt.Errorf("the key '%s' is not a valid key in the config", keys[0])}
 		}
 	}
 	return nil
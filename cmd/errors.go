@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+	"google.golang.org/api/googleapi"
+)
+
+// exit codes mgint returns so scripts can tell failures apart
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitAuth        = 3
+	ExitMondayAPI   = 4
+	ExitQuota       = 5
+	ExitValidation  = 6
+	ExitPartialSync = 7
+	ExitStalePlan   = 8
+)
+
+// usageError is returned for bad arguments, flags or config
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode returns the exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var (
+		usageErr       *usageError
+		boardConfigErr *handlers.ConfigError
+		stalePlanErr   *handlers.StalePlanError
+		authErr        *handlers.AuthError
+		mondayErr      *handlers.MondayAPIError
+		quotaErr       *handlers.QuotaError
+		validationErr  *handlers.ValidationError
+		partialSyncErr *handlers.PartialSyncError
+		failuresErr    *handlers.SyncFailuresError
+		apiErr         *googleapi.Error
+	)
+
+	// a partial sync is checked first, its cause is often one of the errors below
+	switch {
+	case errors.As(err, &partialSyncErr), errors.As(err, &failuresErr):
+		return ExitPartialSync
+	case errors.As(err, &usageErr), errors.As(err, &boardConfigErr):
+		return ExitUsage
+	case errors.As(err, &stalePlanErr):
+		return ExitStalePlan
+	case errors.As(err, &authErr):
+		return ExitAuth
+	case errors.As(err, &quotaErr):
+		return ExitQuota
+	case errors.As(err, &mondayErr):
+		return ExitMondayAPI
+	case errors.As(err, &validationErr):
+		return ExitValidation
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized:
+		return ExitAuth
+	}
+	return ExitError
+}
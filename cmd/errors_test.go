@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+	"google.golang.org/api/googleapi"
+)
+
+func TestExitCode(t *testing.T) {
+	cause := errors.New("cause")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", err: nil, want: ExitOK},
+		{name: "other", err: cause, want: ExitError},
+		{name: "usage", err: &usageError{cause}, want: ExitUsage},
+		{name: "board config", err: &handlers.ConfigError{Err: cause}, want: ExitUsage},
+		{name: "stale plan", err: &handlers.StalePlanError{Err: cause}, want: ExitStalePlan},
+		{name: "auth", err: &handlers.AuthError{Service: "google", Err: cause}, want: ExitAuth},
+		{name: "monday api", err: &handlers.MondayAPIError{Err: cause}, want: ExitMondayAPI},
+		{name: "quota", err: &handlers.QuotaError{Err: cause}, want: ExitQuota},
+		{name: "validation", err: &handlers.ValidationError{Err: cause}, want: ExitValidation},
+		{name: "partial sync", err: &handlers.PartialSyncError{Applied: 1, Total: 2, Err: cause}, want: ExitPartialSync},
+		{name: "item failures", err: &handlers.SyncFailuresError{}, want: ExitPartialSync},
+		{name: "google 401", err: &googleapi.Error{Code: http.StatusUnauthorized}, want: ExitAuth},
+		{name: "google 500", err: &googleapi.Error{Code: http.StatusInternalServerError}, want: ExitError},
+		{name: "wrapped", err: fmt.Errorf("issue syncing: %w", &handlers.QuotaError{Err: cause}), want: ExitQuota},
+
+		// a partial sync wins over the error that stopped it
+		{name: "partial sync of quota", err: &handlers.PartialSyncError{Applied: 1, Total: 2, Err: &handlers.QuotaError{Err: cause}}, want: ExitPartialSync},
+		{name: "partial sync of auth", err: &handlers.PartialSyncError{Applied: 1, Total: 2, Err: &handlers.AuthError{Err: cause}}, want: ExitPartialSync},
+		{name: "wrapped failures", err: fmt.Errorf("2 of 3 board(s) failed to sync: %w", &handlers.SyncFailuresError{}), want: ExitPartialSync},
+		// usage and stale plan errors win over the error they wrap
+		{name: "usage of monday api", err: &usageError{&handlers.MondayAPIError{Err: cause}}, want: ExitUsage},
+		{name: "stale plan of quota", err: &handlers.StalePlanError{Err: &handlers.QuotaError{Err: cause}}, want: ExitStalePlan},
+		// auth failures are reported as auth, not as the api that returned them
+		{name: "auth of google error", err: &handlers.AuthError{Service: "google", Err: &googleapi.Error{Code: http.StatusUnauthorized}}, want: ExitAuth},
+		{name: "quota of monday api", err: &handlers.QuotaError{Err: &handlers.MondayAPIError{Err: cause}}, want: ExitQuota},
+		{name: "monday api of validation", err: &handlers.MondayAPIError{Err: &handlers.ValidationError{Err: cause}}, want: ExitMondayAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("expected exit code %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+	"github.com/spf13/cobra"
+)
+
+var lintFormat string
+
+func init() {
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "output format, 'text' or 'json'")
+	addWeekFlags(lintCmd)
+
+	rootCmd.AddCommand(lintCmd)
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [boardID]",
+	Short: "To check the items of your Monday.com board for problems before syncing it",
+	Long: "check every item of a board the way a sync would, without reading or changing the calendar." +
+		" Exits with code 6 if any issue is found.",
+	Args: usageArgs(func(cmd *cobra.Command, args []string) error {
+		if lintFormat != "text" && lintFormat != "json" {
+			return errors.New("--format must be 'text' or 'json'")
+		}
+		return boardIDArgs(cmd, args)
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		boardID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+
+		opts, err := syncOptions()
+		if err != nil {
+			return err
+		}
+
+		mondayClient := handlers.NewMondayClient(mondayAPIKey)
+		board, err := mondayClient.GetAllItemsInGroupsByBoardId(boardID)
+		if err != nil {
+			return err
+		}
+
+		report, err := handlers.LintBoard(board, opts)
+		if err != nil {
+			return &usageError{err}
+		}
+
+		if lintFormat == "json" {
+			if err := report.WriteJSON(os.Stdout); err != nil {
+				return err
+			}
+		} else {
+			report.Print(os.Stdout)
+		}
+
+		if len(report.Issues) > 0 {
+			return &handlers.ValidationError{Err: fmt.Errorf("%d issue(s) found on board '%s'", len(report.Issues), board.Name)}
+		}
+		return nil
+	},
+}
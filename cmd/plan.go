@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+	"github.com/spf13/cobra"
+)
+
+var planOutputFile string
+
+func init() {
+	planCmd.Flags().StringVarP(&planOutputFile, "output", "o", "", "file to write the plan to, to be used with 'mgint apply'")
+	addFixFlag(planCmd)
+	addSyncOptionFlags(planCmd)
+
+	rootCmd.AddCommand(planCmd)
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan [boardID]",
+	Short: "To review the calendar changes a sync of your Monday.com board would make",
+	Args:  usageArgs(boardIDArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		boardID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+
+		opts, err := syncOptions()
+		if err != nil {
+			return err
+		}
+
+		mondayClient := handlers.NewMondayClient(mondayAPIKey)
+		// get board from monday.com
+		board, err := mondayClient.GetAllItemsInGroupsByBoardId(boardID)
+		if err != nil {
+			return err
+		}
+
+		calendarClient, err := handlers.NewCalendarClient(googleClientID, googleSecret)
+		if err != nil {
+			return err
+		}
+
+		// a board without a calendar is planned against an empty one, apply creates it
+		cal, err := calendarClient.PlanCalendarForBoard(board, syncTimeZone())
+		if err != nil {
+			return err
+		}
+
+		plan, err := calendarClient.PlanSync(board, cal, opts)
+		if err != nil {
+			return err
+		}
+
+		plan.Print(os.Stdout)
+
+		if planOutputFile == "" {
+			return plan.FailuresError()
+		}
+
+		if err := plan.WriteFile(planOutputFile); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nplan saved to %s, run 'mgint apply %s' to make these changes\n", planOutputFile, planOutputFile)
+		return plan.FailuresError()
+	},
+}
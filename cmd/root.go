@@ -13,14 +13,22 @@ import (
 
 const (
 	defaultCfgFile = ".mgint.yaml"
+	// config key holding the column mapping of every board, edited in the config file
+	columnsConfigKey = "columns"
+	// config key holding the event style of every status label, edited in the config file
+	statusesConfigKey = "statuses"
 )
 
 var (
 	cfgFile string
+	// configErr is set when the config file can't be read and returned before any command runs
+	configErr error
 
 	mondayAPIKey   string
 	googleClientID string
 	googleSecret   string
+	timeZone       string
+	conflictWinner string
 )
 
 var configFlags = []configFlag{
@@ -42,6 +50,20 @@ var configFlags = []configFlag{
 		Usage:  "Google secret for google calendar api access",
 		RefVar: &googleSecret,
 	},
+	configFlag{
+		Name:     "timeZone",
+		Value:    "",
+		Usage:    "IANA time zone for due dates and events, e.g. Europe/Berlin (default is the board calendar's time zone)",
+		RefVar:   &timeZone,
+		Optional: true,
+	},
+	configFlag{
+		Name:     "conflictWinner",
+		Value:    "",
+		Usage:    "side kept by --two-way syncs when an item and its event both changed, 'monday' or 'calendar' (default is monday)",
+		RefVar:   &conflictWinner,
+		Optional: true,
+	},
 }
 
 const requiredAnnotationString = "requiredByMgint"
@@ -51,6 +73,8 @@ type configFlag struct {
 	Value  string
 	Usage  string
 	RefVar *string
+	// optional config values are not required by the sync commands
+	Optional bool
 }
 
 var rootCmd = &cobra.Command{
@@ -58,31 +82,58 @@ var rootCmd = &cobra.Command{
 	Short: "A tool to integrate monday.com boards and google calendar",
 	// runs on all subcommands unless they have their own PersistentPreRunE declared
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configErr != nil {
+			return configErr
+		}
 		bindViperFlags(cmd.Flags())
 		err := checkRequiredFlags(cmd.Flags())
 		if err != nil {
-			return err
+			return &usageError{err}
 		}
 		return nil
 	},
+	Args: usageArgs(cobra.NoArgs),
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Usage()
 	},
+	// errors are printed once by Execute
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
-// Execute root command
+// Execute root command, prints the error if there is one. Pass it to ExitCode for the exit code
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	return err
+}
+
+// usageArgs marks the errors of an args validator as usage errors
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return &usageError{err}
+		}
+		return nil
+	}
 }
 
 func init() {
 	cobra.OnInitialize(initViperConfig)
 
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err}
+	})
+
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("config file (default is $HOME/%s)", defaultCfgFile))
 
 	for _, cF := range configFlags {
 		rootCmd.PersistentFlags().StringVar(cF.RefVar, cF.Name, cF.Value, cF.Usage)
-		rootCmd.PersistentFlags().SetAnnotation(cF.Name, requiredAnnotationString, []string{"true"})
+		if !cF.Optional {
+			rootCmd.PersistentFlags().SetAnnotation(cF.Name, requiredAnnotationString, []string{"true"})
+		}
 	}
 }
 
@@ -92,8 +143,8 @@ func initViperConfig() {
 	} else {
 		home, err := homedir.Dir()
 		if err != nil {
-			fmt.Println("Error: ", err)
-			os.Exit(1)
+			configErr = &usageError{fmt.Errorf("issue finding the home directory: %w", err)}
+			return
 		}
 
 		// if config file does not exist create it
@@ -101,7 +152,8 @@ func initViperConfig() {
 		if _, err := os.Stat(cfgFilePath); os.IsNotExist(err) {
 			_, err := os.Create(cfgFilePath)
 			if err != nil {
-				panic(fmt.Sprintf("error creating config file: %v", err))
+				configErr = &usageError{fmt.Errorf("error creating config file: %w", err)}
+				return
 			}
 		}
 
@@ -113,9 +165,10 @@ func initViperConfig() {
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		// stderr keeps stdout clean for output meant to be parsed, like lint --format json and state export
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	} else {
-		panic("err: " + err.Error())
+		configErr = &usageError{fmt.Errorf("issue reading config file: %w", err)}
 	}
 }
 
@@ -125,6 +178,9 @@ func initViperConfig() {
 func checkRequiredFlags(flags *pflag.FlagSet) error {
 	cFlagMap := map[string]*configFlag{}
 	for _, cF := range configFlags {
+		if cF.Optional {
+			continue
+		}
 		cFlagMap[cF.Name] = &cF
 	}
 
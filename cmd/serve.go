@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+	"github.com/spf13/cobra"
+)
+
+// how long open requests get to finish once serve is stopped
+const shutdownTimeout = 10 * time.Second
+
+var (
+	serveAddr         string
+	mondayWebhookPath string
+	googleWebhookURL  string
+)
+
+func init() {
+	// the google oauth redirect already listens on localhost:8080
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8090", "address to listen on for webhooks")
+	serveCmd.Flags().StringVar(&mondayWebhookPath, "monday-path", "/monday", "path Monday.com webhooks are posted to")
+	serveCmd.Flags().StringVar(&googleWebhookURL, "google-url", "", "public https url of this server's google calendar notification path, e.g. https://mgint.example.com/google."+
+		" When set every board calendar is watched for changes")
+	addSyncOptionFlags(serveCmd)
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "To keep calendars in sync by handling Monday.com webhooks",
+	Long: "listen for Monday.com webhooks and re-sync only the item that changed." +
+		" Create webhooks for the create_item, change_column_value, item_deleted and move_item_to_group" +
+		" events on your boards that point to this server.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := syncOptions()
+		if err != nil {
+			return err
+		}
+
+		mondayClient := handlers.NewMondayClient(mondayAPIKey)
+		calendarClient, err := handlers.NewCalendarClient(googleClientID, googleSecret)
+		if err != nil {
+			return err
+		}
+
+		// SIGINT and SIGTERM stop the server and the watch channels registered at google
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(signals)
+
+		mux := http.NewServeMux()
+		server := &http.Server{Addr: serveAddr, Handler: mux}
+		go func() {
+			select {
+			case sig := <-signals:
+				log.Printf("received %s, stopping", sig)
+				cancel()
+			case <-ctx.Done():
+			}
+
+			shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancelShutdown()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				log.Printf("issue stopping the server: %v", err)
+			}
+		}()
+
+		// closed once every watch channel is stopped
+		watcherDone := make(chan struct{})
+
+		webhookHandler := handlers.NewMondayWebhookHandler(mondayClient, calendarClient, opts)
+		mux.Handle(mondayWebhookPath, webhookHandler)
+
+		if googleWebhookURL != "" {
+			googleURL, err := url.Parse(googleWebhookURL)
+			if err != nil {
+				return &usageError{fmt.Errorf("issue parsing --google-url: %w", err)}
+			}
+
+			watcher := handlers.NewCalendarWatcher(calendarClient, googleWebhookURL, webhookHandler.CalendarChanged)
+			webhookHandler.Watcher = watcher
+			mux.Handle(googleURL.Path, watcher)
+
+			go func() {
+				watcher.Run(ctx)
+				close(watcherDone)
+			}()
+
+			calendars, err := calendarClient.ManagedCalendars()
+			if err != nil {
+				cancel()
+				<-watcherDone
+				return err
+			}
+			for _, cal := range calendars {
+				if err := watcher.Watch(cal); err != nil {
+					cancel()
+					<-watcherDone
+					return err
+				}
+			}
+
+			fmt.Printf("listening for google calendar notifications on %s%s\n", serveAddr, googleURL.Path)
+		} else {
+			close(watcherDone)
+		}
+
+		fmt.Printf("listening for Monday.com webhooks on %s%s\n", serveAddr, mondayWebhookPath)
+		err = server.ListenAndServe()
+		cancel()
+		<-watcherDone
+
+		if err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	},
+}
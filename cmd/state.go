@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "To inspect or repair the local state kept between syncs",
+	// the state is local, so none of the api keys are needed
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var resetBoardID string
+
+func init() {
+	stateResetCmd.Flags().StringVar(&resetBoardID, "board", "", "only forget this Monday.com boardID")
+
+	stateCmd.AddCommand(stateShowCmd)
+	stateCmd.AddCommand(stateExportCmd)
+	stateCmd.AddCommand(stateImportCmd)
+	stateCmd.AddCommand(stateResetCmd)
+
+	rootCmd.AddCommand(stateCmd)
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "show the boards, calendars and items in the state",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := handlers.LoadState(handlers.StatePath())
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("state file: %s\n\n", handlers.StatePath())
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BOARD\tCALENDAR\tITEMS\tCACHED EVENTS\tSYNC TOKEN")
+		for _, boardID := range sortedKeys(state.Boards) {
+			boardState := state.Boards[boardID]
+
+			cachedEvents := 0
+			hasSyncToken := "no"
+			if calendarState, ok := state.Calendars[boardState.CalendarID]; ok {
+				cachedEvents = len(calendarState.Events)
+				if calendarState.SyncToken != "" {
+					hasSyncToken = "yes"
+				}
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", boardID, boardState.CalendarID, len(boardState.Items), cachedEvents, hasSyncToken)
+		}
+		w.Flush()
+
+		return nil
+	},
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "write the state as json to a file, or to stdout",
+	Args:  usageArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := handlers.LoadState(handlers.StatePath())
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			return state.Export(os.Stdout)
+		}
+
+		f, err := os.Create(args[0])
+		if err != nil {
+			return fmt.Errorf("issue creating %s: %w", args[0], err)
+		}
+		defer f.Close()
+
+		return state.Export(f)
+	},
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "replace the state with one written by 'mgint state export'",
+	Args: usageArgs(func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("requires a state file created with 'mgint state export'")
+		}
+		return nil
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("issue opening %s: %w", args[0], err)
+		}
+		defer f.Close()
+
+		state, err := handlers.ImportState(handlers.StatePath(), f)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("imported state of %d board(s) from %s\n", len(state.Boards), args[0])
+		return nil
+	},
+}
+
+var stateResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "forget the state, the next sync reads everything from Monday.com and google again",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if resetBoardID == "" {
+			if err := handlers.ResetState(handlers.StatePath()); err != nil {
+				return err
+			}
+			fmt.Println("state reset")
+			return nil
+		}
+
+		state, err := handlers.LoadState(handlers.StatePath())
+		if err != nil {
+			return err
+		}
+
+		state.ResetBoard(resetBoardID)
+		if err := state.Save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("state of board %s reset\n", resetBoardID)
+		return nil
+	},
+}
+
+func sortedKeys(boards map[string]*handlers.BoardState) []string {
+	keys := make([]string, 0, len(boards))
+	for key := range boards {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
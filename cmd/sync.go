@@ -3,58 +3,337 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sebradloff/monday-gcal-integration/handlers"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	dryRun   bool
+	watch    bool
+	interval time.Duration
+
+	tz        string
+	week      string
+	weekOf    string
+	twoWay    bool
+	full      bool
+	keepGoing bool
+	fix       string
 )
 
 func init() {
+	syncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes the sync would make without changing the calendar")
+	syncCmd.Flags().BoolVar(&watch, "watch", false, "keep syncing the boards every --interval until stopped")
+	syncCmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "time between syncs with --watch")
+	addFixFlag(syncCmd)
+	addSyncOptionFlags(syncCmd)
+
 	rootCmd.AddCommand(syncCmd)
 }
 
 var syncCmd = &cobra.Command{
-	Use:   "sync [boardID]",
-	Short: "To sync tasks for your Monday.com board to a Google Calendar",
-	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return errors.New("requires a Monday.com boardID")
+	Use:   "sync [boardID...]",
+	Short: "To sync tasks for your Monday.com boards to Google Calendars",
+	Args:  usageArgs(boardIDsArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		boardIDs := make([]int, 0, len(args))
+		for _, arg := range args {
+			boardID, err := strconv.Atoi(arg)
+			if err != nil {
+				return err
+			}
+			boardIDs = append(boardIDs, boardID)
+		}
+
+		// bad options are usage errors, found before the google sign in and never retried by --watch
+		opts, err := syncOptions()
+		if err != nil {
+			return err
+		}
+		if watch && interval <= 0 {
+			return &usageError{fmt.Errorf("--interval must be more than 0, got %s", interval)}
+		}
+
+		mondayClient := handlers.NewMondayClient(mondayAPIKey)
+		calendarClient, err := handlers.NewCalendarClient(googleClientID, googleSecret)
+		if err != nil {
+			return err
 		}
 
-		if err := boardIDArgValidation(args[0]); err != nil {
-			return fmt.Errorf("issue validating boardID: %v", err)
+		if watch {
+			return watchBoards(mondayClient, calendarClient, boardIDs, opts)
+		}
+
+		// with --keep-going the other boards are still synced, the exit code is the last failure's
+		var syncErr error
+		failedBoards := 0
+		for _, boardID := range boardIDs {
+			if err := syncBoard(mondayClient, calendarClient, boardID, opts); err != nil {
+				if !keepGoing {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Error: board %d: %v\n", boardID, err)
+				syncErr = err
+				failedBoards++
+			}
+		}
+		if syncErr != nil {
+			return fmt.Errorf("%d of %d board(s) failed to sync: %w", failedBoards, len(boardIDs), syncErr)
 		}
 		return nil
 	},
-	Run: func(cmd *cobra.Command, args []string) {
-		boardID, err := strconv.Atoi(args[0])
+}
+
+// syncBoard syncs one board, or only prints its plan with --dry-run
+func syncBoard(mondayClient *handlers.MondayClient, calendarClient *handlers.CalendarClient, boardID int, opts handlers.SyncOptions) error {
+	// get board from monday.com
+	board, err := mondayClient.GetAllItemsInGroupsByBoardId(boardID)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		// a board without a calendar is planned against an empty one, nothing is created
+		cal, err := calendarClient.PlanCalendarForBoard(board, syncTimeZone())
 		if err != nil {
-			panic("")
+			return err
 		}
 
-		mondayClient := handlers.NewMondayClient(mondayAPIKey)
-		// get board from monday.com
-		board, err := mondayClient.GetAllItemsInGroupsByBoardId(boardID)
+		plan, err := calendarClient.PlanSync(board, cal, opts)
 		if err != nil {
-			panic(err)
+			return err
 		}
 
-		calendarClient := handlers.NewCalendarClient(googleClientID, googleSecret)
+		plan.Print(os.Stdout)
+		return plan.FailuresError()
+	}
+
+	// if calendar name does not exist, create it
+	cal, err := calendarClient.CreateCalendarForBoardIfNotExist(board, syncTimeZone())
+	if err != nil {
+		return err
+	}
+
+	plan, err := calendarClient.PlanSync(board, cal, opts)
+	if err != nil {
+		return err
+	}
+
+	// the calendar changes were planned as if the items were already fixed
+	if err := mondayClient.ApplyItemFixes(plan); err != nil {
+		return err
+	}
+
+	// write calendar side changes back to monday.com, before their events are recorded as synced
+	if err := mondayClient.ApplySyncPlan(plan); err != nil {
+		return err
+	}
+
+	// ensure all tasks on the board exist on the calendar in the right days
+	if err := calendarClient.ApplySyncPlan(plan); err != nil {
+		return err
+	}
+
+	fmt.Printf("done syncing tasks of '%s' to google calendar\n", board.Name)
+	plan.PrintFailures(os.Stdout)
+	return plan.FailuresError()
+}
+
+// addSyncOptionFlags adds the flags shared by every command that plans a sync
+func addSyncOptionFlags(cmd *cobra.Command) {
+	addWeekFlags(cmd)
+	cmd.Flags().BoolVar(&twoWay, "two-way", false, "write end time and length changes made on the calendar back to Monday.com")
+	cmd.Flags().BoolVar(&full, "full", false, "check every item, including items unchanged since the last sync")
+	cmd.Flags().BoolVar(&keepGoing, "keep-going", false, "sync every item that can be synced and report the ones that failed at the end")
+}
+
+// addWeekFlags adds the flags that pick the time zone and week, for commands that read a week without syncing it
+func addWeekFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&tz, "tz", "", "IANA time zone for due dates and events, overrides the timeZone config value")
+	cmd.Flags().StringVar(&week, "week", "", "ISO week to sync, e.g. 2026-W43 (default is read from the board or the current week)")
+	cmd.Flags().StringVar(&weekOf, "week-of", "", "any date in the week to sync, e.g. 2026-10-19")
+}
+
+// addFixFlag adds --fix to the commands that change items on Monday.com, sync and plan with apply
+func addFixFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&fix, "fix", "", "fix items with a due date on another day than their group on Monday.com,"+
+		" 'move-item' moves them to the group of their due date, 'clear-due-date' removes the due date")
+}
+
+// syncTimeZone is the --tz flag if given, otherwise the timeZone config value
+func syncTimeZone() string {
+	if tz != "" {
+		return tz
+	}
+	return timeZone
+}
+
+func syncOptions() (handlers.SyncOptions, error) {
+	winner, err := handlers.ParseConflictWinner(conflictWinner)
+	if err != nil {
+		return handlers.SyncOptions{}, &usageError{err}
+	}
+
+	fixMode, err := handlers.ParseFixMode(fix)
+	if err != nil {
+		return handlers.SyncOptions{}, &usageError{err}
+	}
+
+	columns, err := columnMappings()
+	if err != nil {
+		return handlers.SyncOptions{}, err
+	}
+
+	statuses, err := statusStyles()
+	if err != nil {
+		return handlers.SyncOptions{}, err
+	}
+
+	opts := handlers.SyncOptions{
+		TimeZone:       syncTimeZone(),
+		TwoWay:         twoWay,
+		ConflictWinner: winner,
+		Full:           full,
+		KeepGoing:      keepGoing,
+		Fix:            fixMode,
+		Columns:        columns,
+		Statuses:       statuses,
+	}
+
+	if week != "" && weekOf != "" {
+		return opts, &usageError{errors.New("use only one of --week and --week-of")}
+	}
 
-		// if calendar name does not exist, create it
-		cal, err := calendarClient.CreateCalendarForBoardIfNotExist(board)
+	if week != "" {
+		weekTime, err := handlers.ParseISOWeek(week)
 		if err != nil {
-			panic(err)
+			return opts, &usageError{fmt.Errorf("issue parsing --week: %w", err)}
 		}
+		opts.WeekOf = weekTime
+	}
 
-		// ensure all tasks on the board exist on the calendar in the right days
-		_, err = calendarClient.SyncTasksToCalendar(board, cal)
+	if weekOf != "" {
+		weekTime, err := time.Parse(handlers.WeekOfDateFormat, weekOf)
 		if err != nil {
-			panic(err)
+			return opts, &usageError{fmt.Errorf("issue parsing --week-of, use the format 2026-10-19: %w", err)}
 		}
+		opts.WeekOf = weekTime
+	}
 
-		fmt.Println("done syncing tasks to google calendar")
-	},
+	return opts, nil
+}
+
+// columnMappings reads the column mapping of every board from the columns key of the config file, e.g.
+//
+//	columns:
+//	  "1234567890":
+//	    dueDate: date4
+//	    estimate: numeric
+func columnMappings() (map[string]handlers.ColumnMapping, error) {
+	mappings := make(map[string]handlers.ColumnMapping)
+
+	for boardID, value := range viper.GetStringMap(columnsConfigKey) {
+		boardMapping, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, &usageError{fmt.Errorf("the column mapping of board %s should map roles to column ids or types", boardID)}
+		}
+
+		mapping := make(handlers.ColumnMapping)
+		for roleName, column := range boardMapping {
+			role, err := handlers.ParseColumnRole(roleName)
+			if err != nil {
+				return nil, &usageError{fmt.Errorf("issue reading the column mapping of board %s: %w", boardID, err)}
+			}
+			mapping[role] = fmt.Sprint(column)
+		}
+		mappings[boardID] = mapping
+	}
+
+	return mappings, nil
+}
+
+// statusStyles reads the event style of every status label from the statuses key of the config file.
+// A label maps to a color, or to its color, transparency and whether its events are cancelled, e.g.
+//
+//	statuses:
+//	  Stuck: tomato
+//	  Done:
+//	    color: graphite
+//	    transparent: true
+//	  Cancelled:
+//	    cancelled: true
+func statusStyles() (handlers.StatusMapping, error) {
+	styles := make(handlers.StatusMapping)
+
+	// viper lower cases keys, labels are matched ignoring case
+	for label, value := range viper.GetStringMap(statusesConfigKey) {
+		var style handlers.StatusStyle
+		var color string
+
+		switch value := value.(type) {
+		case string:
+			color = value
+		case map[string]interface{}:
+			for key, setting := range value {
+				var ok bool
+				switch key {
+				case "color":
+					color = fmt.Sprint(setting)
+					ok = true
+				case "transparent":
+					style.Transparent, ok = setting.(bool)
+				case "cancelled":
+					style.Cancelled, ok = setting.(bool)
+				}
+				if !ok {
+					return nil, &usageError{fmt.Errorf("issue reading the style of status '%s': '%s: %v' should be color, transparent: true or cancelled: true", label, key, setting)}
+				}
+			}
+		default:
+			return nil, &usageError{fmt.Errorf("the style of status '%s' should be a color or map color, transparent and cancelled", label)}
+		}
+
+		if color != "" {
+			colorID, err := handlers.ParseEventColor(color)
+			if err != nil {
+				return nil, &usageError{fmt.Errorf("issue reading the style of status '%s': %w", label, err)}
+			}
+			style.Color = colorID
+		}
+		styles[strings.ToLower(label)] = style
+	}
+
+	return styles, nil
+}
+
+func boardIDsArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("requires at least one Monday.com boardID")
+	}
+
+	for _, arg := range args {
+		if err := boardIDArgValidation(arg); err != nil {
+			return fmt.Errorf("issue validating boardID: %w", err)
+		}
+	}
+	return nil
+}
+
+func boardIDArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("requires a Monday.com boardID")
+	}
+
+	if err := boardIDArgValidation(args[0]); err != nil {
+		return fmt.Errorf("issue validating boardID: %w", err)
+	}
+	return nil
 }
 
 func boardIDArgValidation(arg string) error {
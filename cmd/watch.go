@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sebradloff/monday-gcal-integration/handlers"
+)
+
+// after a failed sync the wait doubles, up to maxBackoff or the interval when that is longer
+const maxBackoff = time.Hour
+
+// watchBoards syncs the boards every interval until SIGINT or SIGTERM. Failed syncs are logged
+// and retried with exponential backoff, a sync is never interrupted halfway.
+func watchBoards(mondayClient *handlers.MondayClient, calendarClient *handlers.CalendarClient, boardIDs []int, opts handlers.SyncOptions) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Printf("received %s, stopping after the current sync", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	failures := 0
+	for {
+		failed := false
+		for _, boardID := range boardIDs {
+			if ctx.Err() != nil {
+				break
+			}
+
+			if err := safeSyncBoard(mondayClient, calendarClient, boardID, opts); err != nil {
+				log.Printf("issue syncing board %d: %v", boardID, err)
+				failed = true
+			}
+		}
+
+		wait := interval
+		if failed {
+			failures++
+			wait = backoff(interval, failures)
+			log.Printf("sync failed %d time(s) in a row, next sync in %s", failures, wait)
+		} else {
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("stopped watching")
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// safeSyncBoard turns a panic during a sync into an error so the watch loop keeps going
+func safeSyncBoard(mondayClient *handlers.MondayClient, calendarClient *handlers.CalendarClient, boardID int, opts handlers.SyncOptions) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("sync panicked: %v", r)
+		}
+	}()
+
+	return syncBoard(mondayClient, calendarClient, boardID, opts)
+}
+
+func backoff(interval time.Duration, failures int) time.Duration {
+	// a failure never brings the next sync closer than the interval
+	limit := maxBackoff
+	if interval > limit {
+		limit = interval
+	}
+
+	wait := interval
+	for i := 0; i < failures && wait < limit; i++ {
+		wait *= 2
+	}
+	if wait > limit {
+		wait = limit
+	}
+	return wait
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequiredColumnRoles are the columns a board needs for its items to be synced
+var RequiredColumnRoles = []ColumnRole{RoleEstimate, RoleDueDate}
+
+// BoardCompatibility reports whether a board can be synced and, if not, what it is missing
+func BoardCompatibility(board *Board) (bool, []string) {
+	var missing []string
+
+	hasDayGroup := false
+	weekStartNow := weekStart(time.Now(), time.UTC)
+	for _, group := range board.Groups {
+		if _, ok := groupDay(group.Title, weekStartNow, time.UTC); ok {
+			hasDayGroup = true
+			break
+		}
+	}
+	if !hasDayGroup {
+		missing = append(missing, "weekday or date groups")
+	}
+
+	for _, required := range RequiredColumnRoles {
+		if _, ok := board.column(required); !ok {
+			missing = append(missing, fmt.Sprintf("%s column", roleColumnTypes[required]))
+		}
+	}
+
+	return len(missing) == 0, missing
+}
+
+// column types used when a template is missing a required column
+var requiredColumnTypes = map[ColumnRole]string{
+	RoleEstimate: "numbers",
+	RoleDueDate:  "date",
+}
+
+// CreateBoardFromTemplate makes a board for the week of weekOf with a group for every day from
+// Sunday to Saturday and the required columns. Items in the template's day groups are recurring,
+// they are copied to the same weekday with their due dates moved into the new week.
+// Due dates are read and written in loc, which should be the time zone of the Monday.com account.
+func (m *MondayClient) CreateBoardFromTemplate(templateID int, weekOf time.Time, name string, loc *time.Location) (*Board, error) {
+	template, err := m.GetAllItemsInGroupsByBoardId(templateID)
+	if err != nil {
+		return nil, fmt.Errorf("issue getting template board: %w", err)
+	}
+
+	start := weekStart(weekOf, loc)
+	if name == "" {
+		name = fmt.Sprintf("%s - Week of %s", template.Name, start.Format(WeekOfDateFormat))
+	}
+
+	boardID, err := m.DuplicateBoard(templateID, name)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("created board '%s' (%s) from template '%s'", name, boardID, template.Name)
+
+	newBoardID, err := strconv.Atoi(boardID)
+	if err != nil {
+		return nil, fmt.Errorf("new board id '%s' is not a valid int: %w", boardID, err)
+	}
+	board, err := m.GetAllItemsInGroupsByBoardId(newBoardID)
+	if err != nil {
+		return nil, fmt.Errorf("issue getting new board: %w", err)
+	}
+
+	// date groups copied from the template belong to the template's week
+	weekdayGroupIDs := make(map[time.Weekday]string)
+	for _, group := range board.Groups {
+		if weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(group.Title))]; ok {
+			weekdayGroupIDs[weekday] = group.ID
+			continue
+		}
+		if _, ok := groupDay(group.Title, start, loc); ok {
+			if err := m.DeleteGroup(board.ID, group.ID); err != nil {
+				return nil, err
+			}
+			log.Printf("removed group '%s' copied from the template", group.Title)
+		}
+	}
+
+	// monday.com adds new groups to the top of a board, so create them from Saturday back to Sunday
+	for weekday := time.Saturday; weekday >= time.Sunday; weekday-- {
+		if _, ok := weekdayGroupIDs[weekday]; ok {
+			continue
+		}
+		groupID, err := m.CreateGroup(board.ID, weekday.String())
+		if err != nil {
+			return nil, err
+		}
+		weekdayGroupIDs[weekday] = groupID
+		log.Printf("created group '%s'", weekday)
+	}
+
+	for _, required := range RequiredColumnRoles {
+		if _, ok := board.column(required); ok {
+			continue
+		}
+		title := roleColumnTitles[required]
+		columnID, err := m.CreateColumn(board.ID, title, requiredColumnTypes[required])
+		if err != nil {
+			return nil, err
+		}
+		column := Column{ID: columnID, Title: title, Type: requiredColumnTypes[required]}
+		board.Columns = append(board.Columns, column)
+		board.ColumnMap[required] = MappedColumn{Column: column, Source: ColumnSourceTitle}
+		log.Printf("created column '%s'", title)
+	}
+
+	for _, group := range template.Groups {
+		groupDate, ok := groupDay(group.Title, start, loc)
+		if !ok {
+			continue
+		}
+		day := start.AddDate(0, 0, int(groupDate.Weekday()))
+
+		for _, task := range group.Items {
+			columnValues, err := recurringItemColumnValues(template, board, &task, day, loc)
+			if err != nil {
+				return nil, fmt.Errorf("issue copying item '%s': %w", task.Name, err)
+			}
+
+			if _, err := m.CreateItem(board.ID, weekdayGroupIDs[day.Weekday()], task.Name, columnValues); err != nil {
+				return nil, err
+			}
+			log.Printf("copied item '%s' to %s", task.Name, day.Weekday())
+		}
+	}
+
+	return board, nil
+}
+
+// recurringItemColumnValues copies the estimate and due date of a template item to the columns of board,
+// moving the due date to day while keeping its time of day
+func recurringItemColumnValues(template *Board, board *Board, task *Item, day time.Time, loc *time.Location) (map[ID]interface{}, error) {
+	columnValues := make(map[ID]interface{})
+
+	estimateColumn, _ := board.column(RoleEstimate)
+	hours, ok, err := template.itemValue(task, RoleEstimate).Number()
+	if err != nil {
+		return nil, fmt.Errorf("issue converting EstimateHours: %w", err)
+	}
+	if ok {
+		columnValues[estimateColumn.ID] = strconv.FormatFloat(hours, 'f', -1, 64)
+	}
+
+	dueDateColumn, _ := board.column(RoleDueDate)
+	dueDate, hasTime, err := template.itemDueDate(task, loc)
+	if err != nil {
+		return nil, fmt.Errorf("issue parsing DueDateAndTime: %w", err)
+	}
+	if !dueDate.IsZero() && !hasTime {
+		columnValues[dueDateColumn.ID] = map[string]string{
+			"date": day.Format("2006-01-02"),
+		}
+	}
+	if hasTime {
+		// date column values are stored in UTC
+		newDueDate := time.Date(day.Year(), day.Month(), day.Day(), dueDate.Hour(), dueDate.Minute(), 0, 0, loc).UTC()
+		columnValues[dueDateColumn.ID] = map[string]string{
+			"date": newDueDate.Format("2006-01-02"),
+			"time": newDueDate.Format("15:04:05"),
+		}
+	}
+
+	return columnValues, nil
+}
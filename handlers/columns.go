@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ColumnRole is what a column is used for when syncing
+type ColumnRole string
+
+const (
+	RoleDueDate  ColumnRole = "dueDate"
+	RoleEstimate ColumnRole = "estimate"
+	RoleTimeline ColumnRole = "timeline"
+	RoleStatus   ColumnRole = "status"
+	RolePeople   ColumnRole = "people"
+)
+
+// ColumnRoles lists every role in the order they are detected
+var ColumnRoles = []ColumnRole{RoleDueDate, RoleEstimate, RoleTimeline, RoleStatus, RolePeople}
+
+// column types a mapping can name, with the Monday.com type names they stand for.
+// The second names are the ones older api versions return.
+var columnTypeNames = map[string][]string{
+	"date":     {"date"},
+	"numeric":  {"numbers", "numeric"},
+	"timeline": {"timeline", "timerange"},
+	"status":   {"status", "color"},
+	"people":   {"people", "multiple-person"},
+}
+
+// the column type of every role
+var roleColumnTypes = map[ColumnRole]string{
+	RoleDueDate:  "date",
+	RoleEstimate: "numeric",
+	RoleTimeline: "timeline",
+	RoleStatus:   "status",
+	RolePeople:   "people",
+}
+
+// the titles columns were found by before they could be mapped, still preferred when detecting
+var roleColumnTitles = map[ColumnRole]Title{
+	RoleDueDate:  DueDateAndTime,
+	RoleEstimate: EstimateHours,
+	RoleStatus:   TitleStatus,
+}
+
+// roles that change how items are synced, only used when the config maps a column to them
+var configOnlyRoles = map[ColumnRole]bool{
+	RoleTimeline: true,
+}
+
+// ConfigOnly reports whether a role is never detected and needs a column mapped in the config
+func (r ColumnRole) ConfigOnly() bool {
+	return configOnlyRoles[r]
+}
+
+// how a column was picked for a role
+const (
+	ColumnSourceConfig = "config"
+	ColumnSourceTitle  = "title"
+	ColumnSourceType   = "type"
+)
+
+// ColumnMapping picks the column of a role on one board, by column ID or by type
+type ColumnMapping map[ColumnRole]string
+
+// MappedColumn is the column used for a role and how it was picked
+type MappedColumn struct {
+	Column
+	Source string
+}
+
+// ColumnMap is the column used for every role a board has a column for
+type ColumnMap map[ColumnRole]MappedColumn
+
+// ParseColumnRole validates a role name from the config, ignoring case
+func ParseColumnRole(value string) (ColumnRole, error) {
+	for _, role := range ColumnRoles {
+		if strings.EqualFold(string(role), value) {
+			return role, nil
+		}
+	}
+
+	roles := make([]string, 0, len(ColumnRoles))
+	for _, role := range ColumnRoles {
+		roles = append(roles, string(role))
+	}
+	return "", fmt.Errorf("'%s' is not a column role, use one of %s", value, strings.Join(roles, ", "))
+}
+
+// MapColumns picks the column of every role, first from mapping and then detected from the board's columns.
+// A role is detected by the title it used to need, then by the first unused column of its type,
+// except for config only roles, so a column already used for something else doesn't change the sync.
+// Missing required columns are logged, so a renamed or removed column doesn't go unnoticed.
+func (b *Board) MapColumns(mapping ColumnMapping) error {
+	if err := b.mapColumns(mapping); err != nil {
+		return err
+	}
+
+	for _, role := range RequiredColumnRoles {
+		if _, ok := b.ColumnMap[role]; !ok {
+			log.Printf("Warning: board '%s' has no %s column for %s, map one in the config file", b.Name, roleColumnTypes[role], role)
+		}
+	}
+
+	return nil
+}
+
+func (b *Board) mapColumns(mapping ColumnMapping) error {
+	b.ColumnMap = make(ColumnMap)
+
+	for role, value := range mapping {
+		column, ok := b.findColumn(value)
+		if !ok {
+			return &ConfigError{Err: fmt.Errorf("board '%s' has no column with the id or type '%s' to use for %s", b.Name, value, role)}
+		}
+		b.ColumnMap[role] = MappedColumn{Column: column, Source: ColumnSourceConfig}
+	}
+
+	for _, role := range ColumnRoles {
+		if _, ok := b.ColumnMap[role]; ok || role.ConfigOnly() {
+			continue
+		}
+		if mapped, ok := b.detectColumn(role); ok {
+			b.ColumnMap[role] = mapped
+		}
+	}
+
+	return nil
+}
+
+// findColumn finds a column by id, or the first column of a type
+func (b *Board) findColumn(value string) (Column, bool) {
+	for _, column := range b.Columns {
+		if string(column.ID) == value {
+			return column, true
+		}
+	}
+
+	for _, column := range b.Columns {
+		if columnIsType(column, strings.ToLower(value)) {
+			return column, true
+		}
+	}
+
+	return Column{}, false
+}
+
+func (b *Board) detectColumn(role ColumnRole) (MappedColumn, bool) {
+	if title, ok := roleColumnTitles[role]; ok {
+		for _, column := range b.Columns {
+			if column.Title == title {
+				return MappedColumn{Column: column, Source: ColumnSourceTitle}, true
+			}
+		}
+	}
+
+	for _, column := range b.Columns {
+		// the week of column is a date too, but never a due date
+		if column.Title == WeekOf || b.columnMapped(column.ID) {
+			continue
+		}
+		if columnIsType(column, roleColumnTypes[role]) {
+			return MappedColumn{Column: column, Source: ColumnSourceType}, true
+		}
+	}
+
+	return MappedColumn{}, false
+}
+
+func (b *Board) columnMapped(columnID ID) bool {
+	for _, mapped := range b.ColumnMap {
+		if mapped.ID == columnID {
+			return true
+		}
+	}
+	return false
+}
+
+func columnIsType(column Column, columnType string) bool {
+	for _, name := range columnTypeNames[columnType] {
+		if column.Type == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ColumnRoleOf returns the role a column is used for, if any
+func (b *Board) ColumnRoleOf(columnID ID) (ColumnRole, string, bool) {
+	for _, role := range ColumnRoles {
+		if mapped, ok := b.mappedColumns()[role]; ok && mapped.ID == columnID {
+			return role, mapped.Source, true
+		}
+	}
+	return "", "", false
+}
+
+// mappedColumns returns the column map, detecting it the first time if MapColumns was not called
+func (b *Board) mappedColumns() ColumnMap {
+	if b.ColumnMap == nil {
+		// without a mapping there is nothing that can fail
+		b.mapColumns(nil)
+	}
+	return b.ColumnMap
+}
+
+// column returns the column used for role
+func (b *Board) column(role ColumnRole) (Column, bool) {
+	mapped, ok := b.mappedColumns()[role]
+	return mapped.Column, ok
+}
+
+// itemValue returns the value of an item in the column used for role, or nil if there is none
+func (b *Board) itemValue(task *Item, role ColumnRole) *ColumnValue {
+	column, ok := b.column(role)
+	if !ok {
+		return nil
+	}
+
+	for i := range task.ColumnValues {
+		if task.ColumnValues[i].ID == column.ID {
+			return &task.ColumnValues[i]
+		}
+	}
+	return nil
+}
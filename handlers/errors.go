@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+)
+
+// AuthError is returned when Monday.com or google rejects the credentials
+type AuthError struct {
+	Service string
+	Err     error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("%s authentication failed: %v", e.Service, e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// MondayAPIError is returned when a Monday.com api call fails
+type MondayAPIError struct {
+	Err error
+}
+
+func (e *MondayAPIError) Error() string {
+	return fmt.Sprintf("monday.com api error: %v", e.Err)
+}
+
+func (e *MondayAPIError) Unwrap() error {
+	return e.Err
+}
+
+// QuotaError is returned when the google calendar api rate limits or runs out of quota
+type QuotaError struct {
+	Err error
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("google calendar quota exceeded: %v", e.Err)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned when an item on a board can't be turned into an event
+type ValidationError struct {
+	ItemID   string
+	ItemName string
+	Err      error
+}
+
+func (e *ValidationError) Error() string {
+	if e.ItemName == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("item '%s' (%s): %v", e.ItemName, e.ItemID, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError is returned when the config file asks for something the board doesn't have
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// StalePlanError is returned when the calendar changed after a saved plan was made
+type StalePlanError struct {
+	Err error
+}
+
+func (e *StalePlanError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StalePlanError) Unwrap() error {
+	return e.Err
+}
+
+// PartialSyncError is returned when applying a plan stops part way through,
+// Applied of the Total changes were made before Err
+type PartialSyncError struct {
+	Applied int
+	Total   int
+	Err     error
+}
+
+func (e *PartialSyncError) Error() string {
+	return fmt.Sprintf("sync stopped after %d of %d changes: %v", e.Applied, e.Total, e.Err)
+}
+
+func (e *PartialSyncError) Unwrap() error {
+	return e.Err
+}
+
+// SyncFailuresError is returned when a sync kept going past items that failed
+type SyncFailuresError struct {
+	Failures []*ItemFailure
+}
+
+func (e *SyncFailuresError) Error() string {
+	return fmt.Sprintf("%d item(s) failed to sync", len(e.Failures))
+}
+
+// partialSyncError reports a failed change, as a PartialSyncError if earlier changes were already made
+func partialSyncError(applied int, total int, err error) error {
+	if applied == 0 {
+		return err
+	}
+	return &PartialSyncError{Applied: applied, Total: total, Err: err}
+}
+
+// googleError turns google api auth and quota failures into their typed errors
+func googleError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.Code {
+	case http.StatusUnauthorized:
+		return &AuthError{Service: "google", Err: err}
+	case http.StatusTooManyRequests:
+		return &QuotaError{Err: err}
+	case http.StatusForbidden:
+		for _, item := range apiErr.Errors {
+			switch item.Reason {
+			case "rateLimitExceeded", "userRateLimitExceeded", "quotaExceeded":
+				return &QuotaError{Err: err}
+			}
+		}
+	}
+	return err
+}
+
+// mondayError wraps a failed Monday.com api call, auth failures are reported as an AuthError
+func mondayError(err error) error {
+	message := strings.ToLower(err.Error())
+	if strings.Contains(message, "not authenticated") || strings.Contains(message, "unauthorized") {
+		return &AuthError{Service: "monday.com", Err: err}
+	}
+	return &MondayAPIError{Err: err}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// syncCalendarEvents brings the stored events of a calendar up to date. With a sync token only the
+// changes are read, otherwise, or when google answers 410 Gone, the whole calendar is read again.
+// It returns the events that changed, which is nothing after a full read, and passes them to EventsChanged.
+func (c *CalendarClient) syncCalendarEvents(calendarID string) ([]*calendar.Event, error) {
+	c.State.mu.Lock()
+	defer c.State.mu.Unlock()
+
+	calendarState := c.State.calendar(calendarID)
+	incremental := calendarState.SyncToken != ""
+
+	var changed []*calendar.Event
+	nextSyncToken := ""
+	for {
+		call := c.Events.List(calendarID)
+		if incremental {
+			call = call.SyncToken(calendarState.SyncToken)
+		}
+		err := call.Pages(context.Background(), func(page *calendar.Events) error {
+			changed = append(changed, page.Items...)
+			nextSyncToken = page.NextSyncToken
+			return nil
+		})
+
+		if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Code == http.StatusGone && incremental {
+			log.Printf("sync token of calendar %s expired, reading the whole calendar again", calendarID)
+			incremental = false
+			changed = nil
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("issue getting events: %w", googleError(err))
+		}
+		break
+	}
+
+	if !incremental {
+		calendarState.Events = make(map[string]*calendar.Event)
+	}
+	for _, event := range changed {
+		// incremental reads include deleted events
+		if event.Status == "cancelled" {
+			delete(calendarState.Events, event.Id)
+			continue
+		}
+		calendarState.Events[event.Id] = event
+	}
+	calendarState.SyncToken = nextSyncToken
+
+	if err := c.State.save(); err != nil {
+		log.Printf("Warning: failed to save state: %v", err)
+	}
+
+	if !incremental {
+		return nil, nil
+	}
+	// the callback can read the state again, so it runs once the lock is released
+	if len(changed) > 0 && c.EventsChanged != nil {
+		go c.EventsChanged(calendarID, changed)
+	}
+	return changed, nil
+}
+
+// calendarEvents returns the events of a calendar that overlap timeMin to timeMax, read through the stored state.
+// All-day events are placed in the time zone of timeMin. A calendar without an id has no events.
+func (c *CalendarClient) calendarEvents(calendarID string, timeMin time.Time, timeMax time.Time) ([]*calendar.Event, error) {
+	// a calendar planned for a board without one is empty
+	if calendarID == "" {
+		return nil, nil
+	}
+
+	if _, err := c.syncCalendarEvents(calendarID); err != nil {
+		return nil, err
+	}
+
+	c.State.mu.Lock()
+	defer c.State.mu.Unlock()
+
+	var events []*calendar.Event
+	for _, event := range c.State.calendar(calendarID).Events {
+		start, end, err := eventBounds(event, timeMin.Location())
+		if err != nil {
+			continue
+		}
+		// a recurring event is stored once with its first instance, it can have one in any later range
+		if start.Before(timeMax) && (end.After(timeMin) || len(event.Recurrence) > 0) {
+			events = append(events, event)
+		}
+	}
+
+	// keep the order google lists events in, stored events are not ordered
+	sort.Slice(events, func(i, j int) bool {
+		startI, _, _ := eventBounds(events[i], timeMin.Location())
+		startJ, _, _ := eventBounds(events[j], timeMin.Location())
+		if startI.Equal(startJ) {
+			return events[i].Id < events[j].Id
+		}
+		return startI.Before(startJ)
+	})
+
+	return events, nil
+}
+
+// eventBounds returns when an event starts and ends, all day events start and end at midnight in loc
+func eventBounds(event *calendar.Event, loc *time.Location) (time.Time, time.Time, error) {
+	if event.Start == nil || event.End == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("event %s has no start or end", event.Id)
+	}
+
+	if event.Start.DateTime == "" {
+		start, err := time.ParseInLocation(WeekOfDateFormat, event.Start.Date, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end, err := time.ParseInLocation(WeekOfDateFormat, event.End.Date, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return start, end, nil
+	}
+
+	return eventTimes(event)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+type FixMode string
+
+const (
+	// FixNone leaves items with a due date on another day than their group to fail
+	FixNone FixMode = ""
+	// FixMoveItem moves the item to the group of its due date's day
+	FixMoveItem FixMode = "move-item"
+	// FixClearDueDate removes the due date, the item keeps its group and starts at midnight
+	FixClearDueDate FixMode = "clear-due-date"
+)
+
+// ParseFixMode validates a --fix value, an empty value means FixNone
+func ParseFixMode(value string) (FixMode, error) {
+	switch FixMode(value) {
+	case FixNone, FixMoveItem, FixClearDueDate:
+		return FixMode(value), nil
+	}
+	return "", fmt.Errorf("'%s' is not a valid fix, use '%s' or '%s'", value, FixMoveItem, FixClearDueDate)
+}
+
+// ItemFix is a planned change to a Monday.com item whose due date is on another day than its group
+type ItemFix struct {
+	Mode            FixMode   `json:"mode"`
+	ItemID          string    `json:"itemID"`
+	TaskName        string    `json:"taskName"`
+	FromGroupID     string    `json:"fromGroupID"`
+	FromGroup       string    `json:"fromGroup"`
+	ToGroupID       string    `json:"toGroupID,omitempty"`
+	ToGroup         string    `json:"toGroup,omitempty"`
+	DueDateColumnID ID        `json:"dueDateColumnID,omitempty"`
+	DueDate         time.Time `json:"dueDate"`
+}
+
+// planFixes finds the items whose due date is on another day than their group and plans a fix for each.
+// The fix is also made to board so the rest of the plan is worked out as if it was already applied.
+func (p *SyncPlan) planFixes(board *Board, groupDays map[string]time.Time, loc *time.Location, mode FixMode) {
+	type move struct {
+		from, to, item int
+	}
+	var moves []move
+
+	for i := range board.Groups {
+		group := &board.Groups[i]
+		day, ok := groupDays[group.ID]
+		if !ok {
+			continue
+		}
+
+		for j := range group.Items {
+			task := &group.Items[j]
+
+			// items with a timeline are not synced on their group's day, so their due date can be on any day
+			if _, _, hasTimeline, _ := board.itemTimeline(task, loc); hasTimeline {
+				continue
+			}
+
+			// due dates that don't parse are reported when the item is planned
+			dueDate, _, err := board.itemDueDate(task, loc)
+			if err != nil || dueDate.IsZero() || dayKey(dueDate) == dayKey(day) {
+				continue
+			}
+
+			fix := &ItemFix{
+				Mode:        mode,
+				ItemID:      task.ID,
+				TaskName:    task.Name,
+				FromGroupID: group.ID,
+				FromGroup:   group.Title,
+				DueDate:     dueDate,
+			}
+
+			switch mode {
+			case FixMoveItem:
+				to := dayGroupIndex(board, groupDays, dueDate)
+				if to < 0 {
+					log.Printf("can't move '%s', the board has no group for its due date %s", task.Name, dueDate.Format("Monday 2006-01-02"))
+					continue
+				}
+				fix.ToGroupID = board.Groups[to].ID
+				fix.ToGroup = board.Groups[to].Title
+				moves = append(moves, move{from: i, to: to, item: j})
+				log.Printf("planning to move '%s' from group '%s' to '%s' to match its due date", task.Name, fix.FromGroup, fix.ToGroup)
+			case FixClearDueDate:
+				columnValue := board.itemValue(task, RoleDueDate)
+				fix.DueDateColumnID = columnValue.ID
+				cleared := ""
+				columnValue.Text = &cleared
+				columnValue.Value = nil
+				log.Printf("planning to clear the due date %s of '%s' in group '%s'", dueDate.Format("Monday 2006-01-02 15:04"), task.Name, fix.FromGroup)
+			default:
+				continue
+			}
+
+			p.ItemFixes = append(p.ItemFixes, fix)
+		}
+	}
+
+	// items are moved once every group was read, from the back so the indexes stay valid
+	for i := len(moves) - 1; i >= 0; i-- {
+		m := moves[i]
+		from := &board.Groups[m.from]
+		task := from.Items[m.item]
+		from.Items = append(from.Items[:m.item:m.item], from.Items[m.item+1:]...)
+		board.Groups[m.to].Items = append(board.Groups[m.to].Items, task)
+	}
+}
+
+// dayGroupIndex returns the index of the first group of the board for the day of t, or -1
+func dayGroupIndex(board *Board, groupDays map[string]time.Time, t time.Time) int {
+	for i, group := range board.Groups {
+		if day, ok := groupDays[group.ID]; ok && dayKey(day) == dayKey(t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ApplyItemFixes makes the planned fixes on Monday.com. It is run before the calendar changes,
+// which were planned as if the fixes were already made.
+func (m *MondayClient) ApplyItemFixes(plan *SyncPlan) error {
+	for i, fix := range plan.ItemFixes {
+		var err error
+		switch fix.Mode {
+		case FixMoveItem:
+			err = m.MoveItemToGroup(fix.ItemID, fix.ToGroupID)
+		case FixClearDueDate:
+			err = m.ChangeColumnValue(plan.BoardID, fix.ItemID, fix.DueDateColumnID, map[string]string{})
+		default:
+			err = fmt.Errorf("unknown fix '%s' for %s", fix.Mode, fix.TaskName)
+		}
+
+		if err != nil {
+			if !plan.KeepGoing {
+				return partialSyncError(i, len(plan.ItemFixes), err)
+			}
+			plan.addFailure(fix.ItemID, fix.TaskName, fix.FromGroup, err)
+			continue
+		}
+
+		if fix.Mode == FixMoveItem {
+			log.Printf("moved '%s' from group '%s' to '%s'", fix.TaskName, fix.FromGroup, fix.ToGroup)
+		} else {
+			log.Printf("cleared the due date of '%s' in group '%s'", fix.TaskName, fix.FromGroup)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fixTestBoard has an item due on another day than its group in every group but Friday
+func fixTestBoard() *Board {
+	due := func(id string, date string) Item {
+		value := `{"date":"` + date + `","time":"09:00:00"}`
+		return Item{ID: id, Name: "item " + id, ColumnValues: []ColumnValue{{ID: "due", Value: &value, Title: DueDateAndTime, Type: "date"}}}
+	}
+
+	return &Board{
+		ID:      "10",
+		Name:    "Schedule 2026-W43",
+		Columns: []Column{{ID: "due", Title: DueDateAndTime, Type: "date"}},
+		Groups: []Group{
+			{ID: "monday", Title: "Monday", Items: []Item{due("a", "2026-10-21"), due("b", "2026-10-19"), due("c", "2026-10-23")}},
+			{ID: "wednesday", Title: "Wednesday", Items: []Item{due("d", "2026-10-19"), due("e", "2026-10-22")}},
+			{ID: "friday", Title: "Friday", Items: []Item{due("f", "2026-10-23")}},
+		},
+	}
+}
+
+func TestPlanFixes(t *testing.T) {
+	weekStart := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		mode       FixMode
+		wantFixes  []string
+		wantGroups map[string][]string
+		wantDueSet map[string]bool
+	}{
+		{
+			name:      "no fix",
+			mode:      FixNone,
+			wantFixes: nil,
+			wantGroups: map[string][]string{
+				"monday":    {"a", "b", "c"},
+				"wednesday": {"d", "e"},
+				"friday":    {"f"},
+			},
+		},
+		{
+			// e is due on Thursday, which has no group, so it stays
+			name:      "move item",
+			mode:      FixMoveItem,
+			wantFixes: []string{"a", "c", "d"},
+			wantGroups: map[string][]string{
+				"monday":    {"b", "d"},
+				"wednesday": {"e", "a"},
+				"friday":    {"f", "c"},
+			},
+		},
+		{
+			name:      "clear due date",
+			mode:      FixClearDueDate,
+			wantFixes: []string{"a", "c", "d", "e"},
+			wantGroups: map[string][]string{
+				"monday":    {"a", "b", "c"},
+				"wednesday": {"d", "e"},
+				"friday":    {"f"},
+			},
+			wantDueSet: map[string]bool{"a": false, "b": true, "c": false, "d": false, "e": false, "f": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := fixTestBoard()
+			groupDays := make(map[string]time.Time)
+			for _, group := range board.Groups {
+				day, _ := groupDay(group.Title, weekStart, time.UTC)
+				groupDays[group.ID] = day
+			}
+
+			plan := &SyncPlan{}
+			plan.planFixes(board, groupDays, time.UTC, tt.mode)
+
+			var fixes []string
+			for _, fix := range plan.ItemFixes {
+				fixes = append(fixes, fix.ItemID)
+			}
+			if !reflect.DeepEqual(fixes, tt.wantFixes) {
+				t.Errorf("expected fixes of %v, got %v", tt.wantFixes, fixes)
+			}
+
+			for _, group := range board.Groups {
+				var items []string
+				for _, task := range group.Items {
+					items = append(items, task.ID)
+
+					if want, ok := tt.wantDueSet[task.ID]; ok {
+						if got := !board.itemValue(&task, RoleDueDate).IsEmpty(); got != want {
+							t.Errorf("expected item %s to have a due date %t, got %t", task.ID, want, got)
+						}
+					}
+				}
+				if !reflect.DeepEqual(items, tt.wantGroups[group.ID]) {
+					t.Errorf("expected group %s to hold %v, got %v", group.ID, tt.wantGroups[group.ID], items)
+				}
+			}
+		})
+	}
+}
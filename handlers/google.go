@@ -24,9 +24,14 @@ import (
 
 type CalendarClient struct {
 	calendar.Service
+	State *State
+
+	// EventsChanged, when set, is called with the events of a calendar that changed on every incremental read,
+	// whether a sync, a webhook or a push notification made the read
+	EventsChanged func(calendarID string, events []*calendar.Event)
 }
 
-func NewCalendarClient(clientID string, secret string) *CalendarClient {
+func NewCalendarClient(clientID string, secret string) (*CalendarClient, error) {
 	config := &oauth2.Config{
 		ClientID:     clientID,
 		ClientSecret: secret,
@@ -36,239 +41,780 @@ func NewCalendarClient(clientID string, secret string) *CalendarClient {
 
 	ctx := context.Background()
 
-	oauthClient := newOAuthClient(ctx, config)
+	oauthClient, err := newOAuthClient(ctx, config)
+	if err != nil {
+		return nil, err
+	}
 
 	svc, err := calendar.New(oauthClient)
 	if err != nil {
-		log.Fatalf("Unable to create Calendar service: %v", err)
+		return nil, fmt.Errorf("Unable to create Calendar service: %w", err)
 	}
+
+	// a state file that can't be read is left alone rather than overwritten by the next save
+	state, err := LoadState(StatePath())
+	if err != nil {
+		return nil, fmt.Errorf("%w, fix it with 'mgint state import <file>' or start over with 'mgint state reset'", err)
+	}
+
 	return &CalendarClient{
 		Service: *svc,
-	}
+		State:   state,
+	}, nil
 }
 
 const (
-	NewYorkTimeZone              = "America/New_York"
 	DefaultEstimateEventDuration = time.Minute * 30
+
+	// private extended properties tying an event to its monday.com item
+	ItemIDProperty  = "mondayItemID"
+	BoardIDProperty = "mondayBoardID"
+
+	// ownership marker on every event created by this tool, only these events are ever deleted
+	CreatedByProperty = "createdBy"
+	CreatedByValue    = "mgint"
 )
 
-func (c *CalendarClient) CreateCalendarForBoardIfNotExist(board *Board) (*calendar.Calendar, error) {
-	cal := &calendar.Calendar{}
+// SyncOptions changes how a board is synced to its calendar
+type SyncOptions struct {
+	// TimeZone is the IANA time zone used for due dates and event times,
+	// when empty the board calendar's own time zone is used
+	TimeZone string
 
-	calendarList, err := c.CalendarList.List().Do()
-	if err != nil {
-		return cal, fmt.Errorf("Unable to retrieve list of calendars: %v", err)
+	// WeekOf is any day in the week to sync. When zero the week is read from a
+	// "Week Of" column or the board name, falling back to the current week.
+	WeekOf time.Time
+
+	// TwoWay writes end time and length changes made on the calendar back to Monday.com,
+	// ConflictWinner decides which side is kept when both changed since the last sync
+	TwoWay         bool
+	ConflictWinner ConflictWinner
+
+	// Columns maps the columns of a board, by boardID, the columns of boards without a mapping are detected
+	Columns map[string]ColumnMapping
+
+	// Statuses sets the color, transparency or cancelled status of events by the item's status label
+	Statuses StatusMapping
+
+	// Fix is how items with a due date on another day than their group are fixed on Monday.com,
+	// by default they fail to sync
+	Fix FixMode
+
+	// KeepGoing syncs every item it can instead of stopping at the first failure,
+	// the failed items are collected in the plan's Failures
+	KeepGoing bool
+
+	// Full checks every item, instead of skipping items whose updated_at and event
+	// ETag are the same as when they were last synced
+	Full bool
+}
+
+// FindCalendarForBoard returns the calendar created for a board, or nil if there is none yet
+func (c *CalendarClient) FindCalendarForBoard(boardID string) (*calendar.Calendar, error) {
+	// the calendar used last time saves going through every calendar of the account
+	if calendarID := c.State.BoardCalendarID(boardID); calendarID != "" {
+		cal, err := c.Calendars.Get(calendarID).Do()
+		if err == nil {
+			return cal, nil
+		}
+		log.Printf("calendar %s of board %s from the state could not be read, looking for it again: %v", calendarID, boardID, err)
 	}
 
 	var calendarID string
-	for _, calendarItem := range calendarList.Items {
-		// a calendar is deemed created if the calendar description is the boardID
-		if calendarItem.Description == board.ID {
-			calendarID = calendarItem.Id
+	err := c.CalendarList.List().Pages(context.Background(), func(calendarList *calendar.CalendarList) error {
+		for _, calendarItem := range calendarList.Items {
+			// a calendar is deemed created if the calendar description is the boardID
+			if calendarItem.Description == boardID {
+				calendarID = calendarItem.Id
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve list of calendars: %w", googleError(err))
 	}
 
 	if calendarID == "" {
-		cal = &calendar.Calendar{
-			Description: board.ID,
-			Summary:     board.Name,
-			TimeZone:    NewYorkTimeZone,
+		return nil, nil
+	}
+
+	cal, err := c.Calendars.Get(calendarID).Do()
+	if err != nil {
+		return nil, fmt.Errorf("issue getting calendarID %s %w", calendarID, googleError(err))
+	}
+
+	return cal, nil
+}
+
+// CreateCalendarForBoardIfNotExist finds the calendar for a board or creates it in timeZone,
+// if timeZone is empty a new calendar gets the time zone of the primary calendar
+func (c *CalendarClient) CreateCalendarForBoardIfNotExist(board *Board, timeZone string) (*calendar.Calendar, error) {
+	cal, err := c.FindCalendarForBoard(board.ID)
+	if err != nil {
+		return &calendar.Calendar{}, err
+	}
+
+	if cal == nil {
+		cal, err = c.newBoardCalendar(board.ID, board.Name, timeZone)
+		if err != nil {
+			return &calendar.Calendar{}, err
 		}
 		cal, err = c.Calendars.Insert(cal).Do()
 		if err != nil {
-			return cal, fmt.Errorf("issue creating new calendar %s %v", board.Name, err)
+			return cal, fmt.Errorf("issue creating new calendar %s %w", board.Name, googleError(err))
 		}
-	} else {
-		cal, err = c.Calendars.Get(calendarID).Do()
+	}
+
+	c.State.SetBoardCalendarID(board.ID, cal.Id)
+	if err := c.State.Save(); err != nil {
+		log.Printf("Warning: failed to save state: %v", err)
+	}
+
+	return cal, nil
+}
+
+// PlanCalendarForBoard finds the calendar for a board without creating it. A board without a calendar
+// gets the calendar a sync would create, which has no Id and is planned against as an empty calendar.
+func (c *CalendarClient) PlanCalendarForBoard(board *Board, timeZone string) (*calendar.Calendar, error) {
+	cal, err := c.FindCalendarForBoard(board.ID)
+	if err != nil || cal != nil {
+		return cal, err
+	}
+
+	log.Printf("board '%s' has no calendar yet, planning against an empty calendar", board.Name)
+	return c.newBoardCalendar(board.ID, board.Name, timeZone)
+}
+
+// CreatePlanCalendar creates the calendar of a plan made by PlanCalendarForBoard for a board without one
+func (c *CalendarClient) CreatePlanCalendar(plan *SyncPlan) error {
+	if plan.CalendarID != "" {
+		return nil
+	}
+
+	board := &Board{ID: plan.BoardID, Name: plan.BoardName}
+	cal, err := c.CreateCalendarForBoardIfNotExist(board, plan.CalendarTimeZone)
+	if err != nil {
+		return err
+	}
+	plan.CalendarID = cal.Id
+	return nil
+}
+
+// newBoardCalendar returns the calendar to create for a board, without creating it
+func (c *CalendarClient) newBoardCalendar(boardID string, boardName string, timeZone string) (*calendar.Calendar, error) {
+	if timeZone == "" {
+		primary, err := c.Calendars.Get("primary").Do()
 		if err != nil {
-			return cal, fmt.Errorf("issue getting calendarID %s %v", calendarID, err)
+			return nil, fmt.Errorf("issue getting the primary calendar time zone: %w", googleError(err))
 		}
+		timeZone = primary.TimeZone
 	}
 
-	return cal, nil
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, fmt.Errorf("issue loading time zone '%s': %w", timeZone, err)
+	}
+
+	return &calendar.Calendar{
+		Description: boardID,
+		Summary:     boardName,
+		TimeZone:    timeZone,
+	}, nil
 }
 
-func (c *CalendarClient) SyncTasksToCalendar(board *Board, cal *calendar.Calendar) (map[int]*calendar.Events, error) {
-	// current time rounded down to the begining of the day
-	loc, _ := time.LoadLocation(NewYorkTimeZone)
-	currentTime := time.Now()
-	currentDateTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, loc)
+// SyncTasksToCalendar plans the changes needed for the calendar to reflect the board and applies them
+func (c *CalendarClient) SyncTasksToCalendar(board *Board, cal *calendar.Calendar, opts SyncOptions) (*SyncPlan, error) {
+	plan, err := c.PlanSync(board, cal, opts)
+	if err != nil {
+		return plan, err
+	}
 
-	currentWeekday := currentDateTime.Weekday()
+	return plan, c.ApplySyncPlan(plan)
+}
 
-	weekdayDatetime := make(map[int]time.Time)
-	weekdayDatetime[int(currentWeekday)] = currentDateTime
+// PlanSync works out which events have to be added, deleted and updated without changing the calendar.
+// With opts.Fix the planned fixes are also made to board, Monday.com is only changed by ApplyItemFixes.
+func (c *CalendarClient) PlanSync(board *Board, cal *calendar.Calendar, opts SyncOptions) (*SyncPlan, error) {
+	plan, loc, err := newSyncPlan(board, cal, opts)
+	if err != nil {
+		return plan, err
+	}
 
-	for i := 0; i <= 6; i++ {
-		if int(currentWeekday) == i {
-			continue
+	groupDays := plan.planGroupDays(board, loc)
+	for _, group := range board.Groups {
+		if _, ok := groupDays[group.ID]; !ok {
+			log.Printf("skipping group '%s', its title is not a weekday or a date", group.Title)
 		}
-		diff := i - int(currentWeekday)
-		duration := time.Hour * 24 * time.Duration(diff)
+	}
 
-		weekdayTime := currentDateTime.Add(duration)
+	if len(groupDays) == 0 {
+		return plan, nil
+	}
+	// events dragged to a day without a group are still found
+	plan.coverWeek()
 
-		weekdayDatetime[int(weekdayTime.Weekday())] = weekdayTime
+	if opts.Fix != FixNone {
+		plan.planFixes(board, groupDays, loc, opts.Fix)
 	}
 
-	// get events from every day this week
-	allEvents := make(map[int]*calendar.Events)
-	for k, v := range weekdayDatetime {
-		endOfDay := v.Add(time.Hour*23 + time.Minute*59)
+	// items with a timeline get one event over their dates instead of an event on their group's day
+	timelineItems, timelineItemIDs, err := plan.boardTimelineItems(board, groupDays, loc, opts)
+	if err != nil {
+		return plan, err
+	}
 
-		events, err := c.Events.List(cal.Id).TimeMin(v.Format(time.RFC3339)).TimeMax(endOfDay.Format(time.RFC3339)).Do()
-		if err != nil {
-			return allEvents, fmt.Errorf("issue getting events: %v", err)
+	// the day of every item synced on its group's day
+	itemDays := make(map[string]string)
+	for _, group := range board.Groups {
+		if day, ok := groupDays[group.ID]; ok {
+			for _, task := range dayItems(group.Items, timelineItemIDs) {
+				itemDays[task.ID] = dayKey(day)
+			}
 		}
+	}
 
-		allEvents[k] = events
+	// get events from every day the board covers, bucketed by the day they start on.
+	// Events of timeline items are matched by item id wherever they start, events of other items
+	// are bucketed on their item's day so an event dragged to another day is still matched to its item.
+	dayEvents := make(map[string][]*calendar.Event)
+	timelineEvents := make(map[string]*calendar.Event)
+	events, err := c.calendarEvents(cal.Id, plan.TimeMin, plan.TimeMax)
+	if err != nil {
+		return plan, err
 	}
+	for _, event := range events {
+		plan.Snapshot[event.Id] = event.Etag
 
-	var days = map[string]int{
-		"Sunday":    0,
-		"Monday":    1,
-		"Tuesday":   2,
-		"Wednesday": 3,
-		"Thursday":  4,
-		"Friday":    5,
-		"Saturday":  6,
+		if itemID := eventItemID(event); timelineItemIDs[itemID] {
+			if _, seen := timelineEvents[itemID]; !seen {
+				timelineEvents[itemID] = event
+				continue
+			}
+		}
+
+		key := eventDayKey(event, loc)
+		if itemDay, ok := itemDays[eventItemID(event)]; ok {
+			key = itemDay
+		}
+		dayEvents[key] = append(dayEvents[key], event)
 	}
 
-	// add tasks as events that are missing
-	var eventsToAdd []*calendar.Event
+	// pair every task with the event that represents it, once per day as several groups can share a day
+	// and an event must only be adopted by one of their items
+	dayTasks := make(map[string][]Item)
 	for _, group := range board.Groups {
-		weekdayInt := days[group.Title]
+		day, ok := groupDays[group.ID]
+		if !ok {
+			continue
+		}
+		dayTasks[dayKey(day)] = append(dayTasks[dayKey(day)], dayItems(group.Items, timelineItemIDs)...)
+	}
+	dayMatches := make(map[string]map[string]*calendar.Event)
+	for key, tasks := range dayTasks {
+		dayMatches[key] = matchEventsToTasks(tasks, dayEvents[key])
+	}
 
+	matchedEvents := make(map[string]map[string]*calendar.Event)
+	for _, group := range board.Groups {
+		day, ok := groupDays[group.ID]
+		if !ok {
+			continue
+		}
+		matchedEvents[group.ID] = make(map[string]*calendar.Event)
 		for _, task := range group.Items {
-			taskExistsAsEvent := false
+			if event, ok := dayMatches[dayKey(day)][task.ID]; ok {
+				matchedEvents[group.ID][task.ID] = event
+			}
+		}
+	}
+
+	// add tasks as events that are missing
+	for _, group := range board.Groups {
+		day, ok := groupDays[group.ID]
+		if !ok {
+			continue
+		}
 
-			for _, event := range allEvents[weekdayInt].Items {
-				if event.Summary == task.Name {
-					taskExistsAsEvent = true
-					break
+		for _, task := range dayItems(group.Items, timelineItemIDs) {
+			if _, taskExistsAsEvent := matchedEvents[group.ID][task.ID]; !taskExistsAsEvent {
+				if err := plan.planTask(board, &group, &task, nil, day, loc, opts); err != nil {
+					if !opts.KeepGoing {
+						return plan, err
+					}
+					plan.addFailure(task.ID, task.Name, group.Title, err)
 				}
 			}
+		}
+	}
 
-			if !taskExistsAsEvent {
-				eventToAdd, err := taskToEvent(&task, weekdayDatetime[weekdayInt])
-				if err != nil {
-					return allEvents, fmt.Errorf("error converting task to event: %v", err)
+	// remove events that no longer exist as tasks, several groups can share a day
+	removedEvents := make(map[string]bool)
+	for _, group := range board.Groups {
+		day, ok := groupDays[group.ID]
+		if !ok {
+			continue
+		}
+
+		for _, event := range dayEvents[dayKey(day)] {
+			eventIsStillTask := false
+
+			for groupID, otherDay := range groupDays {
+				if !otherDay.Equal(day) {
+					continue
 				}
-				eventsToAdd = append(eventsToAdd, eventToAdd)
+				for _, matchedEvent := range matchedEvents[groupID] {
+					if event.Id == matchedEvent.Id {
+						eventIsStillTask = true
+						break
+					}
+				}
+			}
+
+			if eventIsStillTask || removedEvents[event.Id] {
+				continue
 			}
+			removedEvents[event.Id] = true
+
+			if !eventCreatedByTool(event) {
+				log.Printf("leaving event '%s' on %s alone, it was not created by mgint", event.Summary, day.Format(WeekOfDateFormat))
+				continue
+			}
+
+			plan.Changes = append(plan.Changes, &EventChange{
+				Action:   ActionDelete,
+				ItemID:   eventItemID(event),
+				TaskName: event.Summary,
+				Day:      day,
+				Old:      event,
+			})
 		}
 	}
 
-	for _, event := range eventsToAdd {
-		_, err := c.Events.Insert(cal.Id, event).Do()
-		if err != nil {
-			return allEvents, fmt.Errorf("issue creating events %s: %v", event.Summary, err)
+	// monday.com items due date column overwrites gcal end time
+	for _, group := range board.Groups {
+		day, ok := groupDays[group.ID]
+		if !ok {
+			continue
+		}
+
+		for _, task := range dayItems(group.Items, timelineItemIDs) {
+			event, ok := matchedEvents[group.ID][task.ID]
+			if !ok {
+				continue
+			}
+
+			if !opts.Full && c.itemUnchanged(board.ID, &task, event) {
+				plan.skipTask(&task, event)
+				continue
+			}
+
+			if err := plan.planTask(board, &group, &task, event, day, loc, opts); err != nil {
+				if !opts.KeepGoing {
+					return plan, err
+				}
+				plan.addFailure(task.ID, task.Name, group.Title, err)
+			}
+		}
+	}
+
+	for _, item := range timelineItems {
+		event := timelineEvents[item.task.ID]
+		if event != nil && !opts.Full && c.itemUnchanged(board.ID, item.task, event) {
+			plan.skipTask(item.task, event)
+			continue
+		}
+
+		if err := plan.planTimelineTask(board, item, event, loc); err != nil {
+			if !opts.KeepGoing {
+				return plan, err
+			}
+			plan.addFailure(item.task.ID, item.task.Name, item.group.Title, err)
 		}
 	}
 
-	// remove events that no longer exist as tasks
-	var eventsToRemove []*calendar.Event
+	return plan, nil
+}
+
+// planGroupDays returns the day of every group titled with a weekday of the week or a date,
+// and sets the plan's time range to cover every one of those days
+func (p *SyncPlan) planGroupDays(board *Board, loc *time.Location) map[string]time.Time {
+	groupDays := make(map[string]time.Time)
 	for _, group := range board.Groups {
-		weekdayInt := days[group.Title]
+		day, ok := groupDay(group.Title, p.WeekStart, loc)
+		if !ok {
+			continue
+		}
+		groupDays[group.ID] = day
 
-		for _, event := range allEvents[weekdayInt].Items {
-			eventIsStillTask := false
+		endOfDay := day.AddDate(0, 0, 1).Add(-time.Minute)
+		if p.TimeMin.IsZero() || day.Before(p.TimeMin) {
+			p.TimeMin = day
+		}
+		if endOfDay.After(p.TimeMax) {
+			p.TimeMax = endOfDay
+		}
+	}
+	return groupDays
+}
+
+// itemUnchanged reports whether neither an item nor its event changed since the item was last synced
+func (c *CalendarClient) itemUnchanged(boardID string, task *Item, event *calendar.Event) bool {
+	itemState, ok := c.State.Item(boardID, task.ID)
+	if !ok || task.UpdatedAt == "" {
+		return false
+	}
+
+	return itemState.UpdatedAt == task.UpdatedAt && itemState.EventID == event.Id && itemState.ETag == event.Etag
+}
+
+// newSyncPlan starts an empty plan for the week being synced and loads the time zone to sync in
+func newSyncPlan(board *Board, cal *calendar.Calendar, opts SyncOptions) (*SyncPlan, *time.Location, error) {
+	plan := &SyncPlan{
+		BoardID:          board.ID,
+		BoardName:        board.Name,
+		CalendarID:       cal.Id,
+		CalendarTimeZone: cal.TimeZone,
+		CalendarSummary:  cal.Summary,
+		CreatedAt:        time.Now(),
+		Snapshot:         make(map[string]string),
+		KeepGoing:        opts.KeepGoing,
+	}
+
+	timeZone := opts.TimeZone
+	if timeZone == "" {
+		timeZone = cal.TimeZone
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return plan, nil, fmt.Errorf("issue loading time zone '%s': %w", timeZone, err)
+	}
+
+	if err := board.MapColumns(opts.Columns[board.ID]); err != nil {
+		return plan, nil, err
+	}
+	board.StatusStyles = opts.Statuses
+
+	// the week comes from the options, then the board, then defaults to the current week
+	weekOf := opts.WeekOf
+	if weekOf.IsZero() {
+		if boardWeek, ok := boardWeekOf(board); ok {
+			weekOf = boardWeek
+		} else {
+			weekOf = time.Now().In(loc)
+		}
+	}
+	plan.WeekStart = weekStart(weekOf, loc)
+
+	return plan, loc, nil
+}
+
+// planTask adds the changes needed for the event of a task to match it, event is nil when the task has no event yet
+func (p *SyncPlan) planTask(board *Board, group *Group, task *Item, event *calendar.Event, day time.Time, loc *time.Location, opts SyncOptions) error {
+	if event == nil {
+		eventToAdd, err := taskToEvent(board, task, day, loc)
+		if err != nil {
+			return fmt.Errorf("error converting task to event: %w", err)
+		}
+		// a cancelled event is removed, there is nothing to add
+		if eventToAdd.Status == "cancelled" {
+			return nil
+		}
+		p.Changes = append(p.Changes, &EventChange{
+			Action:    ActionAdd,
+			ItemID:    task.ID,
+			TaskName:  task.Name,
+			UpdatedAt: task.UpdatedAt,
+			Group:     group.Title,
+			Day:       day,
+			New:       eventToAdd,
+		})
+		return nil
+	}
+
+	// all-day events are left by items that had a timeline, they are rewritten rather than written back
+	if opts.TwoWay && eventCreatedByTool(event) && eventItemID(event) == task.ID && !eventAllDay(event) {
+		mondayEvent, err := taskToEvent(board, task, day, loc)
+		if err != nil {
+			return fmt.Errorf("error converting task to event: %w", err)
+		}
+
+		mondayChange, updatedEvent, err := planCalendarWriteBack(board, task, event, mondayEvent, opts.ConflictWinner)
+		if err != nil {
+			return fmt.Errorf("error checking calendar changes of '%s': %w", task.Name, err)
+		}
 
-			for _, task := range group.Items {
-				if event.Summary == task.Name {
-					eventIsStillTask = true
-					break
+		if mondayChange != nil {
+			// an event dragged to another day moves its item to that day's group
+			if newDay := mondayChange.NewDueDate.In(loc); dayKey(newDay) != dayKey(day) {
+				if err := p.planDraggedItem(board, group, task, newDay, loc, opts.Fix); err != nil {
+					return err
 				}
 			}
 
-			if !eventIsStillTask {
-				eventsToRemove = append(eventsToRemove, event)
-			}
+			mondayChange.Day = day
+			p.MondayChanges = append(p.MondayChanges, mondayChange)
+			p.Changes = append(p.Changes, &EventChange{
+				Action:    ActionUpdate,
+				ItemID:    task.ID,
+				TaskName:  task.Name,
+				UpdatedAt: task.UpdatedAt,
+				Group:     group.Title,
+				Day:       day,
+				Old:       event,
+				New:       updatedEvent,
+				WriteBack: true,
+			})
+			return nil
 		}
 	}
 
-	for _, event := range eventsToRemove {
-		err := c.Events.Delete(cal.Id, event.Id).Do()
+	shouldUpdateEvent, err := eventNeedsToBeUpdated(board, task, event, loc)
+	if err != nil {
+		return fmt.Errorf("error checking if eventNeedsToBeUpdated: %w", err)
+	}
+
+	style, err := board.itemStatusStyle(task)
+	if err != nil {
+		return err
+	}
+
+	// renamed items, adopted events that are not tagged yet, items whose status label changed
+	// and events on another day than their item need to be rewritten too
+	if event.Summary != task.Name || eventItemID(event) != task.ID || !style.matches(event) || !eventOnDay(event, day, loc) {
+		shouldUpdateEvent = true
+	}
+
+	if shouldUpdateEvent {
+		eventToBeUpdated, err := taskToEvent(board, task, day, loc)
 		if err != nil {
-			return allEvents, fmt.Errorf("issue deleting event %s: %v", event.Summary, err)
+			return fmt.Errorf("error converting task to event: %w", err)
 		}
+		eventToBeUpdated.Id = event.Id
+		p.Changes = append(p.Changes, &EventChange{
+			Action:    ActionUpdate,
+			ItemID:    task.ID,
+			TaskName:  task.Name,
+			UpdatedAt: task.UpdatedAt,
+			Group:     group.Title,
+			Day:       day,
+			Old:       event,
+			New:       eventToBeUpdated,
+		})
+		return nil
 	}
 
-	// monday.com items due date column overwrites gcal end time
-	var eventsToUpdate []*calendar.Event
-	for _, group := range board.Groups {
-		weekdayInt := days[group.Title]
+	p.InSync = append(p.InSync, &SyncedItem{
+		ItemID:    task.ID,
+		EventID:   event.Id,
+		UpdatedAt: task.UpdatedAt,
+		ETag:      event.Etag,
+	})
 
-		for _, event := range allEvents[weekdayInt].Items {
+	return nil
+}
 
-			for _, task := range group.Items {
-				if event.Summary == task.Name {
-					shouldUpdateEvent, err := eventNeedsToBeUpdated(&task, event)
-					if err != nil {
-						return allEvents, fmt.Errorf("error checking if eventNeedsToBeUpdated: %v", err)
-					}
+// ApplySyncPlan carries out the changes of a plan on the plan's calendar and records the synced items in the state
+func (c *CalendarClient) ApplySyncPlan(plan *SyncPlan) error {
+	defer func() {
+		if err := c.State.Save(); err != nil {
+			log.Printf("Warning: failed to save state: %v", err)
+		}
+	}()
 
-					if shouldUpdateEvent {
-						eventToBeUpdated, err := taskToEvent(&task, weekdayDatetime[weekdayInt])
-						if err != nil {
-							return allEvents, fmt.Errorf("error converting task to event: %v", err)
-						}
-						eventToBeUpdated.Id = event.Id
-						eventsToUpdate = append(eventsToUpdate, eventToBeUpdated)
-					}
-				}
+	for i, change := range plan.Changes {
+		if err := c.applyChange(plan, change); err != nil {
+			if !plan.KeepGoing {
+				return partialSyncError(i, len(plan.Changes), err)
 			}
+			plan.addFailure(change.ItemID, change.TaskName, change.GroupOrDay(), err)
 		}
 	}
 
-	for _, event := range eventsToUpdate {
-		_, err := c.Events.Update(cal.Id, event.Id, event).Do()
+	for _, item := range plan.InSync {
+		c.State.SetItem(plan.BoardID, item.ItemID, ItemState{EventID: item.EventID, UpdatedAt: item.UpdatedAt, ETag: item.ETag})
+	}
+
+	return nil
+}
+
+// applyChange makes a single planned change on the plan's calendar
+func (c *CalendarClient) applyChange(plan *SyncPlan, change *EventChange) error {
+	switch change.Action {
+	case ActionAdd:
+		event, err := c.Events.Insert(plan.CalendarID, change.New).Do()
+		if err != nil {
+			return fmt.Errorf("issue creating events %s: %w", change.New.Summary, googleError(err))
+		}
+		c.State.SetItem(plan.BoardID, change.ItemID, ItemState{EventID: event.Id, UpdatedAt: change.UpdatedAt, ETag: event.Etag})
+	case ActionDelete:
+		call := c.Events.Delete(plan.CalendarID, change.Old.Id)
+		if change.Old.Etag != "" {
+			call.Header().Set("If-Match", change.Old.Etag)
+		}
+		err := call.Do()
+		if err != nil {
+			return fmt.Errorf("issue deleting event %s: %w", change.Old.Summary, googleError(err))
+		}
+		if itemState, ok := c.State.Item(plan.BoardID, change.ItemID); ok && itemState.EventID == change.Old.Id {
+			c.State.DeleteItem(plan.BoardID, change.ItemID)
+		}
+	case ActionUpdate:
+		call := c.Events.Update(plan.CalendarID, change.New.Id, change.New)
+		if change.Old.Etag != "" {
+			call.Header().Set("If-Match", change.Old.Etag)
+		}
+		event, err := call.Do()
 		if err != nil {
-			panic(fmt.Sprintf("issue updating event %s : %v", event.Summary, err))
+			return fmt.Errorf("issue updating event %s: %w", change.New.Summary, googleError(err))
 		}
+		c.State.SetItem(plan.BoardID, change.ItemID, ItemState{EventID: event.Id, UpdatedAt: change.UpdatedAt, ETag: event.Etag})
+	default:
+		return fmt.Errorf("unknown plan action '%s' for %s", change.Action, change.TaskName)
 	}
 
-	return allEvents, nil
+	return nil
 }
 
-func eventNeedsToBeUpdated(task *Item, event *calendar.Event) (bool, error) {
-	var taskDueDate time.Time
-	var taskEstimate time.Duration
+// CheckSyncPlanIsCurrent compares the events on the calendar with the event ETags recorded
+// when the plan was made and returns an error if anything was added, changed or removed since
+func (c *CalendarClient) CheckSyncPlanIsCurrent(plan *SyncPlan) error {
+	// the board had no calendar when the plan was made, it still has to have none
+	if plan.CalendarID == "" {
+		cal, err := c.FindCalendarForBoard(plan.BoardID)
+		if err != nil {
+			return err
+		}
+		if cal != nil {
+			return &StalePlanError{Err: fmt.Errorf("the calendar '%s' was created for the board after the plan was made", cal.Summary)}
+		}
+		return nil
+	}
 
-	for _, columnValue := range task.ColumnValues {
-		var err error
+	// events are read the way PlanSync read them, so the same events fall in the plan's time range
+	events, err := c.calendarEvents(plan.CalendarID, plan.TimeMin, plan.TimeMax)
+	if err != nil {
+		return err
+	}
 
-		if columnValue.Title == EstimateHours {
-			taskEstimate, err = time.ParseDuration(*columnValue.Text + "h")
-			if err != nil {
-				return false, fmt.Errorf("issue converting EstimateHours: %v", err)
+	current := make(map[string]string)
+	for _, event := range events {
+		current[event.Id] = event.Etag
+	}
+
+	for eventID, etag := range plan.Snapshot {
+		currentEtag, ok := current[eventID]
+		if !ok {
+			return &StalePlanError{Err: fmt.Errorf("the event %s was removed from the calendar after the plan was made", eventID)}
+		}
+		if currentEtag != etag {
+			return &StalePlanError{Err: fmt.Errorf("the event %s was changed on the calendar after the plan was made", eventID)}
+		}
+	}
+
+	for eventID := range current {
+		if _, ok := plan.Snapshot[eventID]; !ok {
+			return &StalePlanError{Err: fmt.Errorf("the event %s was added to the calendar after the plan was made", eventID)}
+		}
+	}
+
+	return nil
+}
+
+// matchEventsToTasks pairs tasks with events by the monday.com item id stored on the event.
+// Events that have no item id yet are adopted by a name match the first time they are seen.
+func matchEventsToTasks(tasks []Item, events []*calendar.Event) map[string]*calendar.Event {
+	matched := make(map[string]*calendar.Event)
+	claimed := make(map[string]bool)
+
+	for _, event := range events {
+		itemID := eventItemID(event)
+		if itemID == "" {
+			continue
+		}
+		for _, task := range tasks {
+			if task.ID == itemID {
+				matched[task.ID] = event
+				claimed[event.Id] = true
+				break
 			}
 		}
+	}
 
-		if columnValue.Title == DueDateAndTime {
-			if *columnValue.Text != "" {
-				loc, _ := time.LoadLocation(NewYorkTimeZone)
-				taskDueDate, err = time.ParseInLocation(DueDateAndTimeFormat, *columnValue.Text, loc)
-				if err != nil {
-					return false, fmt.Errorf("issue parsing DueDateAndTime: %v", err)
-				}
+	for _, task := range tasks {
+		if _, ok := matched[task.ID]; ok {
+			continue
+		}
+		for _, event := range events {
+			if claimed[event.Id] || eventItemID(event) != "" {
+				continue
+			}
+			if event.Summary == task.Name {
+				matched[task.ID] = event
+				claimed[event.Id] = true
+				break
 			}
 		}
 	}
 
+	return matched
+}
+
+// eventItemID returns the monday.com item id an event was tagged with, if any
+func eventItemID(event *calendar.Event) string {
+	if event.ExtendedProperties == nil {
+		return ""
+	}
+	return event.ExtendedProperties.Private[ItemIDProperty]
+}
+
+// eventBoardID returns the monday.com board id an event was tagged with, if any
+func eventBoardID(event *calendar.Event) string {
+	if event.ExtendedProperties == nil {
+		return ""
+	}
+	return event.ExtendedProperties.Private[BoardIDProperty]
+}
+
+// eventCreatedByTool reports whether an event carries the ownership marker set by taskToEvent
+func eventCreatedByTool(event *calendar.Event) bool {
+	if event.ExtendedProperties == nil {
+		return false
+	}
+	return event.ExtendedProperties.Private[CreatedByProperty] == CreatedByValue
+}
+
+func eventNeedsToBeUpdated(board *Board, task *Item, event *calendar.Event, loc *time.Location) (bool, error) {
+	taskEstimate, err := board.itemEstimate(task)
+	if err != nil {
+		return false, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue converting EstimateHours: %w", err)}
+	}
+
+	taskDueDate, dueDateHasTime, err := board.itemDueDate(task, loc)
+	if err != nil {
+		return false, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue parsing DueDateAndTime: %w", err)}
+	}
+	// a due date without a time starts at midnight like an item without a due date
+	if !dueDateHasTime {
+		taskDueDate = time.Time{}
+	}
+
+	// an all-day event is left by an item whose timeline was cleared
+	if eventAllDay(event) {
+		return true, nil
+	}
+
 	var eventEndDateTime time.Time
 	var eventStartDateTime time.Time
 	var eventDuration time.Duration
 
-	loc, _ := time.LoadLocation(NewYorkTimeZone)
-	eventEndDateTime, err := time.ParseInLocation(time.RFC3339, event.End.DateTime, loc)
+	eventEndDateTime, err = time.ParseInLocation(time.RFC3339, event.End.DateTime, loc)
 	if err != nil {
-		return false, fmt.Errorf("issue parsing event end datetime: %v", err)
+		return false, fmt.Errorf("issue parsing event end datetime: %w", err)
 	}
 
 	eventStartDateTime, err = time.ParseInLocation(time.RFC3339, event.Start.DateTime, loc)
 	if err != nil {
-		return false, fmt.Errorf("issue parsing event start datetime: %v", err)
+		return false, fmt.Errorf("issue parsing event start datetime: %w", err)
 	}
 
 	eventDuration = eventEndDateTime.Sub(eventStartDateTime)
@@ -287,45 +833,39 @@ func eventNeedsToBeUpdated(task *Item, event *calendar.Event) (bool, error) {
 	return true, nil
 }
 
-func taskToEvent(task *Item, defaultStartDateTime time.Time) (*calendar.Event, error) {
+func taskToEvent(board *Board, task *Item, defaultStartDateTime time.Time, loc *time.Location) (*calendar.Event, error) {
 	event := &calendar.Event{}
 
-	estimateEventDuration := DefaultEstimateEventDuration
-
-	defaultEndDateTime := defaultStartDateTime.Add(estimateEventDuration)
 	var endDateTime time.Time
 
 	defaultEventStatus := "tentative"
 	eventStatus := defaultEventStatus
 
-	for _, columnValue := range task.ColumnValues {
-		var err error
+	estimateEventDuration, err := board.itemEstimate(task)
+	if err != nil {
+		return event, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue converting EstimateHours: %w", err)}
+	}
 
-		if columnValue.Title == EstimateHours {
-			estimateEventDuration, err = time.ParseDuration(*columnValue.Text + "h")
-			if err != nil {
-				return event, fmt.Errorf("issue converting EstimateHours: %v", err)
-			}
-		}
+	dueDate, dueDateHasTime, err := board.itemDueDate(task, loc)
+	if err != nil {
+		return event, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue parsing DueDateAndTime: %w", err)}
+	}
 
-		if columnValue.Title == DueDateAndTime {
-			if *columnValue.Text != "" {
-				loc, _ := time.LoadLocation(NewYorkTimeZone)
-				endDateTime, err = time.ParseInLocation(DueDateAndTimeFormat, *columnValue.Text, loc)
-				if err != nil {
-					return event, fmt.Errorf("issue parsing DueDateAndTime: %v", err)
-				}
+	if !dueDate.IsZero() {
+		if dayKey(dueDate) != dayKey(defaultStartDateTime) {
+			err := fmt.Errorf(
+				"the due date in Monday.com is on '%s' instead of '%s'."+
+					" A task in the group for '%s', if it has a due date, should be set to the same day as the group."+
+					" Please fix in Monday.com by removing the due date or changing the date and time.",
+				dueDate.Format("Monday 2006-01-02"), defaultStartDateTime.Format("Monday 2006-01-02"),
+				defaultStartDateTime.Format("Monday 2006-01-02"))
+			return event, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: err}
+		}
+		eventStatus = "confirmed"
 
-				if endDateTime.Weekday() != defaultEndDateTime.Weekday() {
-					err := fmt.Errorf(
-						"the task: '%s' has a due date in Monday.com on the weekday '%s' instead of '%s'."+
-							" A task in the group '%s', if it has a due date, should be set to the same day as the group name."+
-							" Please fix in Monday.com by removing the due date or changing the date and time.",
-						task.Name, endDateTime.Weekday(), defaultEndDateTime.Weekday(), defaultEndDateTime.Weekday())
-					return event, err
-				}
-				eventStatus = "confirmed"
-			}
+		// a due date without a time starts at midnight like an item without a due date
+		if dueDateHasTime {
+			endDateTime = dueDate
 		}
 	}
 
@@ -342,15 +882,30 @@ func taskToEvent(task *Item, defaultStartDateTime time.Time) (*calendar.Event, e
 		Description: "Created by cli tool",
 		End: &calendar.EventDateTime{
 			DateTime: endDateTime.Format(time.RFC3339),
-			TimeZone: NewYorkTimeZone,
+			TimeZone: loc.String(),
 		},
 		Start: &calendar.EventDateTime{
 			DateTime: startDateTime.Format(time.RFC3339),
-			TimeZone: NewYorkTimeZone,
+			TimeZone: loc.String(),
 		},
 		Summary: task.Name,
 		Status:  eventStatus,
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{
+				ItemIDProperty:      task.ID,
+				BoardIDProperty:     board.ID,
+				CreatedByProperty:   CreatedByValue,
+				SyncedStartProperty: startDateTime.Format(time.RFC3339),
+				SyncedEndProperty:   endDateTime.Format(time.RFC3339),
+			},
+		},
+	}
+
+	style, err := board.itemStatusStyle(task)
+	if err != nil {
+		return event, err
 	}
+	style.apply(event)
 
 	return event, nil
 }
@@ -395,17 +950,20 @@ func saveToken(file string, token *oauth2.Token) {
 	gob.NewEncoder(f).Encode(token)
 }
 
-func newOAuthClient(ctx context.Context, config *oauth2.Config) *http.Client {
+func newOAuthClient(ctx context.Context, config *oauth2.Config) (*http.Client, error) {
 	cacheFile := tokenCacheFile(config)
 	token, err := tokenFromFile(cacheFile)
 	if err != nil {
-		token = tokenFromWeb(ctx, config)
+		token, err = tokenFromWeb(ctx, config)
+		if err != nil {
+			return nil, &AuthError{Service: "google", Err: err}
+		}
 		saveToken(cacheFile, token)
 	} else {
 		log.Printf("Using cached token %#v from %q", token, cacheFile)
 	}
 
-	return config.Client(ctx, token)
+	return config.Client(ctx, token), nil
 }
 
 type customServer struct {
@@ -413,7 +971,7 @@ type customServer struct {
 	wg sync.WaitGroup
 }
 
-func tokenFromWeb(ctx context.Context, config *oauth2.Config) *oauth2.Token {
+func tokenFromWeb(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
 	ch := make(chan string)
 	randState := fmt.Sprintf("st%d", time.Now().UnixNano())
 
@@ -445,13 +1003,15 @@ func tokenFromWeb(ctx context.Context, config *oauth2.Config) *oauth2.Token {
 	}
 	defer server.Close()
 
+	// listen before opening the browser so the redirect can't be missed
+	ln, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("issue listening for the oauth redirect: %w", err)
+	}
+
 	server.wg.Add(1)
 	go func() {
 		defer server.wg.Done()
-		ln, err := net.Listen("tcp", server.Addr)
-		if err != nil {
-			panic(err)
-		}
 		server.Serve(ln)
 	}()
 
@@ -464,9 +1024,9 @@ func tokenFromWeb(ctx context.Context, config *oauth2.Config) *oauth2.Token {
 
 	token, err := config.Exchange(ctx, code)
 	if err != nil {
-		log.Fatalf("Token exchange error: %v", err)
+		return nil, fmt.Errorf("Token exchange error: %w", err)
 	}
-	return token
+	return token, nil
 }
 
 func openURL(url string) {
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// PlanItemSync works out the changes for a single item of a board. The item's event is found by its
+// item id anywhere in the week being synced, so items moved to another day are followed.
+// board only needs the item's own group, the week is read in full whatever groups it has.
+// Events of the item in other weeks are left alone, a board can be synced week after week.
+func (c *CalendarClient) PlanItemSync(board *Board, itemID string, cal *calendar.Calendar, opts SyncOptions) (*SyncPlan, error) {
+	plan, loc, err := newSyncPlan(board, cal, opts)
+	if err != nil {
+		return plan, err
+	}
+
+	var task *Item
+	var group *Group
+	for i := range board.Groups {
+		for j := range board.Groups[i].Items {
+			if board.Groups[i].Items[j].ID == itemID {
+				group = &board.Groups[i]
+				task = &board.Groups[i].Items[j]
+			}
+		}
+	}
+	if task == nil {
+		return plan, fmt.Errorf("item %s is not on board '%s'", itemID, board.Name)
+	}
+
+	groupDays := plan.planGroupDays(board, loc)
+	plan.coverWeek()
+
+	// an item with a timeline has one event over its dates, whichever group it is in
+	from, to, hasTimeline, err := board.itemTimeline(task, loc)
+	if err != nil {
+		return plan, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue parsing timeline: %w", err)}
+	}
+	if hasTimeline {
+		plan.coverDays(from, to)
+	}
+
+	events, err := c.itemEvents(board.ID, cal.Id, itemID, plan.TimeMin, plan.TimeMax)
+	if err != nil {
+		return plan, err
+	}
+
+	day, ok := groupDays[group.ID]
+	if !ok {
+		log.Printf("removing events of '%s', its group '%s' is not a weekday or a date", task.Name, group.Title)
+		plan.planItemEventsRemoval(events, loc)
+		return plan, nil
+	}
+
+	var event *calendar.Event
+	if len(events) > 0 {
+		event = events[0]
+		plan.planItemEventsRemoval(events[1:], loc)
+	}
+
+	if hasTimeline {
+		if event != nil && !opts.Full && c.itemUnchanged(board.ID, task, event) {
+			plan.skipTask(task, event)
+			return plan, nil
+		}
+
+		item := &timelineItem{group: group, task: task, from: from, to: to}
+		if err := plan.planTimelineTask(board, item, event, loc); err != nil {
+			return plan, err
+		}
+		return plan, nil
+	}
+
+	// an item moved to another group keeps its event, planTask moves it with the item
+	if event != nil && !opts.Full && c.itemUnchanged(board.ID, task, event) {
+		plan.skipTask(task, event)
+		return plan, nil
+	}
+
+	if err := plan.planTask(board, group, task, event, day, loc, opts); err != nil {
+		return plan, err
+	}
+
+	return plan, nil
+}
+
+// PlanItemRemoval works out the changes for an item that was deleted from a board,
+// only its events in the week being synced are removed
+func (c *CalendarClient) PlanItemRemoval(board *Board, itemID string, cal *calendar.Calendar) (*SyncPlan, error) {
+	plan, loc, err := newSyncPlan(board, cal, SyncOptions{})
+	if err != nil {
+		return plan, err
+	}
+	// the board of a deleted item can't be read, so it has no groups
+	plan.planGroupDays(board, loc)
+	plan.coverWeek()
+
+	events, err := c.itemEvents(board.ID, cal.Id, itemID, plan.TimeMin, plan.TimeMax)
+	if err != nil {
+		return plan, err
+	}
+
+	plan.planItemEventsRemoval(events, loc)
+
+	return plan, nil
+}
+
+func (p *SyncPlan) planItemEventsRemoval(events []*calendar.Event, loc *time.Location) {
+	for _, event := range events {
+		if !eventCreatedByTool(event) {
+			log.Printf("leaving event '%s' alone, it was not created by mgint", event.Summary)
+			continue
+		}
+
+		day, _ := time.ParseInLocation(WeekOfDateFormat, eventDayKey(event, loc), loc)
+		p.Changes = append(p.Changes, &EventChange{
+			Action:   ActionDelete,
+			ItemID:   eventItemID(event),
+			TaskName: event.Summary,
+			Day:      day,
+			Old:      event,
+		})
+	}
+}
+
+// itemEvents returns the events tagged with a monday.com item id that overlap timeMin to timeMax,
+// read through the stored state. The event recorded for the item in the state comes first.
+func (c *CalendarClient) itemEvents(boardID string, calendarID string, itemID string, timeMin time.Time, timeMax time.Time) ([]*calendar.Event, error) {
+	calendarEvents, err := c.calendarEvents(calendarID, timeMin, timeMax)
+	if err != nil {
+		return nil, err
+	}
+
+	itemState, _ := c.State.Item(boardID, itemID)
+
+	var events []*calendar.Event
+	for _, event := range calendarEvents {
+		if eventItemID(event) != itemID {
+			continue
+		}
+		if event.Id == itemState.EventID {
+			events = append([]*calendar.Event{event}, events...)
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const testCalendarID = "cal"
+
+// newTestCalendarClient returns a client whose stored events are already read, google only
+// answers that nothing changed since
+func newTestCalendarClient(t *testing.T, events ...*calendar.Event) *CalendarClient {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(`{"items": [], "nextSyncToken": "next"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	svc, err := calendar.New(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.BasePath = server.URL + "/"
+
+	state := &State{
+		Boards: make(map[string]*BoardState),
+		Calendars: map[string]*CalendarState{
+			testCalendarID: {SyncToken: "token", Events: make(map[string]*calendar.Event)},
+		},
+	}
+	for _, event := range events {
+		state.Calendars[testCalendarID].Events[event.Id] = event
+	}
+
+	return &CalendarClient{Service: *svc, State: state}
+}
+
+func testEvent(id string, itemID string, start time.Time) *calendar.Event {
+	return &calendar.Event{
+		Id:      id,
+		Etag:    id + "-etag",
+		Summary: "item " + itemID,
+		Start:   &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+		End:     &calendar.EventDateTime{DateTime: start.Add(DefaultEstimateEventDuration).Format(time.RFC3339)},
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{
+				ItemIDProperty:    itemID,
+				CreatedByProperty: CreatedByValue,
+			},
+		},
+	}
+}
+
+func TestPlanItemRemovalDeletesEventsOfTheWeek(t *testing.T) {
+	// the webhook of a deleted item only knows the board id, so the week is the current one
+	thisWeek := weekStart(time.Now().UTC(), time.UTC)
+	event := testEvent("e1", "1", thisWeek.AddDate(0, 0, 3).Add(9*time.Hour))
+	lastWeek := testEvent("e2", "1", thisWeek.AddDate(0, 0, -4).Add(9*time.Hour))
+	otherItem := testEvent("e3", "2", thisWeek.AddDate(0, 0, 3).Add(10*time.Hour))
+	client := newTestCalendarClient(t, event, lastWeek, otherItem)
+
+	plan, err := client.PlanItemRemoval(&Board{ID: "10"}, "1", &calendar.Calendar{Id: testCalendarID, TimeZone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(plan.Changes))
+	}
+	if change := plan.Changes[0]; change.Action != ActionDelete || change.Old.Id != event.Id {
+		t.Errorf("expected a delete of %s, got %s of %s", event.Id, change.Action, change.Old.Id)
+	}
+}
+
+func TestPlanItemSyncMovesEventOfMovedItem(t *testing.T) {
+	// the item was moved from Wednesday to Friday, its board only has the group it is in now
+	board := &Board{
+		ID:   "10",
+		Name: "Schedule 2026-W43",
+		Groups: []Group{
+			{ID: "friday", Title: "Friday", Items: []Item{{ID: "1", Name: "item 1"}}},
+		},
+	}
+	wednesday := time.Date(2026, time.October, 21, 0, 0, 0, 0, time.UTC)
+	event := testEvent("e1", "1", wednesday)
+	client := newTestCalendarClient(t, event)
+
+	plan, err := client.PlanItemSync(board, "1", &calendar.Calendar{Id: testCalendarID, TimeZone: "UTC"}, SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(plan.Changes))
+	}
+	change := plan.Changes[0]
+	if change.Action != ActionUpdate || change.Old.Id != event.Id {
+		t.Fatalf("expected an update of %s, got %s", event.Id, change.Action)
+	}
+	if want := "2026-10-23"; dayKey(change.Day) != want {
+		t.Errorf("expected the event to move to %s, got %s", want, dayKey(change.Day))
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type LintCheck string
+
+const (
+	CheckMissingColumn LintCheck = "missing-column"
+	CheckUnknownGroup  LintCheck = "unknown-group"
+	CheckDueDate       LintCheck = "due-date"
+	CheckDueDateDay    LintCheck = "due-date-day"
+	CheckEstimate      LintCheck = "estimate"
+	CheckTimeline      LintCheck = "timeline"
+	CheckStartTooEarly LintCheck = "start-before-midnight"
+	CheckDuplicateName LintCheck = "duplicate-name"
+)
+
+// LintIssue is a problem on a board that would stop an item, or a group of items, from syncing as expected
+type LintIssue struct {
+	Check    LintCheck `json:"check"`
+	ItemID   string    `json:"itemID,omitempty"`
+	ItemName string    `json:"itemName,omitempty"`
+	Group    string    `json:"group,omitempty"`
+	Message  string    `json:"message"`
+}
+
+// LintReport is every issue found on a board for the week it would be synced as
+type LintReport struct {
+	BoardID   string       `json:"boardID"`
+	BoardName string       `json:"boardName"`
+	WeekStart time.Time    `json:"weekStart"`
+	Issues    []*LintIssue `json:"issues"`
+}
+
+// LintBoard runs the checks a sync does on every item of a board without reading or changing the calendar.
+// Due dates are read in opts.TimeZone, or the local time zone when it is empty.
+func LintBoard(board *Board, opts SyncOptions) (*LintReport, error) {
+	loc := time.Local
+	if opts.TimeZone != "" {
+		var err error
+		loc, err = time.LoadLocation(opts.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("issue loading time zone '%s': %w", opts.TimeZone, err)
+		}
+	}
+
+	weekOf := opts.WeekOf
+	if weekOf.IsZero() {
+		if boardWeek, ok := boardWeekOf(board); ok {
+			weekOf = boardWeek
+		} else {
+			weekOf = time.Now().In(loc)
+		}
+	}
+
+	report := &LintReport{
+		BoardID:   board.ID,
+		BoardName: board.Name,
+		WeekStart: weekStart(weekOf, loc),
+		Issues:    []*LintIssue{},
+	}
+
+	if err := board.MapColumns(opts.Columns[board.ID]); err != nil {
+		return nil, err
+	}
+	for _, required := range RequiredColumnRoles {
+		if _, ok := board.column(required); !ok {
+			report.add(CheckMissingColumn, nil, "", fmt.Sprintf("the board has no %s column for %s", roleColumnTypes[required], required))
+		}
+	}
+
+	// item names are compared per day, several groups can share a day
+	dayNames := make(map[string]map[string]bool)
+	for _, group := range board.Groups {
+		day, ok := groupDay(group.Title, report.WeekStart, loc)
+		if !ok {
+			report.add(CheckUnknownGroup, nil, group.Title, "the group title is not a weekday or a date, its items are not synced")
+			continue
+		}
+
+		names, ok := dayNames[dayKey(day)]
+		if !ok {
+			names = make(map[string]bool)
+			dayNames[dayKey(day)] = names
+		}
+
+		for i := range group.Items {
+			task := &group.Items[i]
+
+			if names[task.Name] {
+				report.add(CheckDuplicateName, task, group.Title,
+					fmt.Sprintf("another item on %s has the same name, events without an item id can be matched to the wrong item", day.Format("Monday 2006-01-02")))
+			}
+			names[task.Name] = true
+
+			report.lintItem(board, task, group.Title, day, loc)
+		}
+	}
+
+	return report, nil
+}
+
+// lintItem checks the due date and estimate of an item the way taskToEvent reads them
+func (r *LintReport) lintItem(board *Board, task *Item, group string, day time.Time, loc *time.Location) {
+	estimate, err := board.itemEstimate(task)
+	estimateOK := err == nil
+	if err != nil {
+		r.add(CheckEstimate, task, group, fmt.Sprintf("the estimate is not a number of hours: %v", err))
+	}
+
+	// items with a timeline are synced over its days instead of their group's day
+	_, _, hasTimeline, err := board.itemTimeline(task, loc)
+	if err != nil {
+		r.add(CheckTimeline, task, group, fmt.Sprintf("the timeline is not a date range: %v", err))
+	}
+
+	dueDate, dueDateHasTime, err := board.itemDueDate(task, loc)
+	if err != nil {
+		r.add(CheckDueDate, task, group, fmt.Sprintf("the due date is not a date: %v", err))
+	} else if hasTimeline {
+		return
+	} else if !dueDate.IsZero() && dayKey(dueDate) != dayKey(day) {
+		r.add(CheckDueDateDay, task, group, fmt.Sprintf("the due date is on %s instead of %s",
+			dueDate.Format("Monday 2006-01-02"), day.Format("Monday 2006-01-02")))
+	}
+
+	// items without a due date time start at midnight, so only a due date time can push the start into the day before
+	if estimateOK && dueDateHasTime && dueDate.Add(-estimate).Before(day) && dayKey(dueDate) == dayKey(day) {
+		r.add(CheckStartTooEarly, task, group, fmt.Sprintf("an estimate of %s before the due date at %s starts the event the day before",
+			estimate, dueDate.Format("15:04")))
+	}
+}
+
+func (r *LintReport) add(check LintCheck, task *Item, group string, message string) {
+	issue := &LintIssue{
+		Check:   check,
+		Group:   group,
+		Message: message,
+	}
+	if task != nil {
+		issue.ItemID = task.ID
+		issue.ItemName = task.Name
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// Print writes the issues one per line
+func (r *LintReport) Print(w io.Writer) {
+	fmt.Fprintf(w, "Lint of board '%s', week of %s: %d issue(s)\n", r.BoardName, r.WeekStart.Format(WeekOfDateFormat), len(r.Issues))
+
+	for _, issue := range r.Issues {
+		var where []string
+		if issue.Group != "" {
+			where = append(where, fmt.Sprintf("group '%s'", issue.Group))
+		}
+		if issue.ItemID != "" {
+			where = append(where, fmt.Sprintf("item '%s' (%s)", issue.ItemName, issue.ItemID))
+		}
+		if len(where) == 0 {
+			where = append(where, "board")
+		}
+		fmt.Fprintf(w, "%-22s %s: %s\n", issue.Check, strings.Join(where, ", "), issue.Message)
+	}
+}
+
+// WriteJSON writes the report as indented json
+func (r *LintReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("issue encoding lint report: %w", err)
+	}
+	return nil
+}
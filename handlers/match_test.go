@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestMatchEventsToTasks(t *testing.T) {
+	tagged := func(id string, summary string, itemID string) *calendar.Event {
+		return &calendar.Event{
+			Id:      id,
+			Summary: summary,
+			ExtendedProperties: &calendar.EventExtendedProperties{
+				Private: map[string]string{ItemIDProperty: itemID},
+			},
+		}
+	}
+	untagged := func(id string, summary string) *calendar.Event {
+		return &calendar.Event{Id: id, Summary: summary}
+	}
+
+	tests := []struct {
+		name   string
+		tasks  []Item
+		events []*calendar.Event
+		// item id to the id of the event it should be matched with
+		want map[string]string
+	}{
+		{
+			name:   "by item id",
+			tasks:  []Item{{ID: "1", Name: "renamed"}},
+			events: []*calendar.Event{tagged("e1", "old name", "1")},
+			want:   map[string]string{"1": "e1"},
+		},
+		{
+			name:   "item id wins over name",
+			tasks:  []Item{{ID: "1", Name: "write"}},
+			events: []*calendar.Event{untagged("e1", "write"), tagged("e2", "old name", "1")},
+			want:   map[string]string{"1": "e2"},
+		},
+		{
+			name:   "untagged event adopted by name",
+			tasks:  []Item{{ID: "1", Name: "write"}},
+			events: []*calendar.Event{untagged("e1", "write")},
+			want:   map[string]string{"1": "e1"},
+		},
+		{
+			name:   "event tagged for another item isn't adopted by name",
+			tasks:  []Item{{ID: "1", Name: "write"}},
+			events: []*calendar.Event{tagged("e1", "write", "2")},
+			want:   map[string]string{},
+		},
+		{
+			name:   "same name claims an event once",
+			tasks:  []Item{{ID: "1", Name: "write"}, {ID: "2", Name: "write"}},
+			events: []*calendar.Event{untagged("e1", "write")},
+			want:   map[string]string{"1": "e1"},
+		},
+		{
+			name:   "event claimed by id isn't adopted by another item's name",
+			tasks:  []Item{{ID: "1", Name: "write"}, {ID: "2", Name: "read"}},
+			events: []*calendar.Event{tagged("e1", "read", "1"), untagged("e2", "read")},
+			want:   map[string]string{"1": "e1", "2": "e2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := matchEventsToTasks(tt.tasks, tt.events)
+
+			if len(matched) != len(tt.want) {
+				t.Errorf("expected %d matches, got %d", len(tt.want), len(matched))
+			}
+			for itemID, eventID := range tt.want {
+				if event, ok := matched[itemID]; !ok || event.Id != eventID {
+					t.Errorf("expected item %s to match event %s, got %v", itemID, eventID, event)
+				}
+			}
+		})
+	}
+}
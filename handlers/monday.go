@@ -2,12 +2,38 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/machinebox/graphql"
 )
 
-const mondayRootPath = "https://api.monday.com/v2/"
+const (
+	mondayRootPath   = "https://api.monday.com/v2/"
+	mondayAPIVersion = "2023-10"
+
+	// items are read in pages of itemsPageLimit, a group with more than maxItemsPages pages is refused
+	itemsPageLimit = 100
+	maxItemsPages  = 50
+
+	boardsPageLimit = 50
+)
+
+// fields requested for every item, shared by the first and following item pages
+const itemFields = `
+					id
+					name
+					updated_at
+					column_values {
+						id
+						text
+						value
+						column {
+							title
+							type
+						}
+					}
+`
 
 type MondayClient struct {
 	Client *graphql.Client
@@ -20,15 +46,39 @@ type Data struct {
 }
 
 type Board struct {
-	Name   string  `json:"name"`
-	ID     string  `json:"id"`
-	Groups []Group `json:"groups"`
+	Name       string     `json:"name"`
+	ID         string     `json:"id"`
+	ItemsCount int        `json:"items_count"`
+	Workspace  *Workspace `json:"workspace"`
+	Columns    []Column   `json:"columns"`
+	Groups     []Group    `json:"groups"`
+	// ColumnMap is the column used for each role, set by MapColumns
+	ColumnMap ColumnMap `json:"-"`
+	// StatusStyles is how events are shown by the item's status label, set from SyncOptions.Statuses
+	StatusStyles StatusMapping `json:"-"`
+}
+
+type Workspace struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Column struct {
+	ID    ID     `json:"id"`
+	Title Title  `json:"title"`
+	Type  string `json:"type"`
 }
 
 type Group struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
-	Items []Item `json:"items"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Items     []Item    `json:"items"`
+	ItemsPage ItemsPage `json:"items_page"`
+}
+
+type ItemsPage struct {
+	Cursor string `json:"cursor"`
+	Items  []Item `json:"items"`
 }
 
 type Item struct {
@@ -38,10 +88,38 @@ type Item struct {
 	ColumnValues []ColumnValue `json:"column_values"`
 }
 
+// ColumnValue is the value of an item in a column. Text is what Monday.com displays,
+// Value is the json the typed accessors in values.go decode.
 type ColumnValue struct {
 	ID    ID      `json:"id"`
 	Text  *string `json:"text"`
+	Value *string `json:"value"`
 	Title Title   `json:"title"`
+	Type  string  `json:"type"`
+}
+
+// UnmarshalJSON reads the title and type from the nested column object used by the api
+func (c *ColumnValue) UnmarshalJSON(data []byte) error {
+	type columnValue ColumnValue
+	var raw struct {
+		columnValue
+		Column *struct {
+			Title Title  `json:"title"`
+			Type  string `json:"type"`
+		} `json:"column"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*c = ColumnValue(raw.columnValue)
+	if raw.Column != nil && raw.Column.Title != "" {
+		c.Title = raw.Column.Title
+	}
+	if raw.Column != nil && raw.Column.Type != "" {
+		c.Type = raw.Column.Type
+	}
+	return nil
 }
 
 type ID string
@@ -68,35 +146,34 @@ func NewMondayClient(apiKey string) *MondayClient {
 }
 
 func (m *MondayClient) GetAllItemsInGroupsByBoardId(boardID int) (*Board, error) {
-	req := graphql.NewRequest(`
-			query getAllItemsInGroupsByBoardId ($boardID: [Int]) {
+	req := m.newRequest(`
+			query getAllItemsInGroupsByBoardId ($boardID: [ID!], $limit: Int!) {
 			boards(ids: $boardID) {
 				name
 				id
+				columns {
+					id
+					title
+					type
+				}
 				groups{
+				id
 				title
-				items(limit: 20) {
-					id
-					name
-					updated_at
-					column_values {
-						id
-						text
-						title
-					}
+				items_page(limit: $limit) {
+					cursor
+					items {` + itemFields + `}
 				}
 				}
 			}
 			}
 			`)
 	req.Var("boardID", boardID)
-	req.Header.Set("Authorization", m.APIKey)
-	req.Header.Set("Cache-Control", "no-cache")
+	req.Var("limit", itemsPageLimit)
 
 	ctx := context.Background()
 
 	var graphqlResponse Data
-	if err := m.Client.Run(ctx, req, &graphqlResponse); err != nil {
+	if err := m.run(ctx, req, &graphqlResponse); err != nil {
 		return nil, err
 	}
 
@@ -104,5 +181,326 @@ func (m *MondayClient) GetAllItemsInGroupsByBoardId(boardID int) (*Board, error)
 		return nil, fmt.Errorf("a board with id %d does not exist", boardID)
 	}
 
-	return &graphqlResponse.Boards[0], nil
+	board := &graphqlResponse.Boards[0]
+
+	// every group has its own cursor, keep reading pages until it runs out
+	for i := range board.Groups {
+		group := &board.Groups[i]
+		group.Items = group.ItemsPage.Items
+
+		cursor := group.ItemsPage.Cursor
+		for page := 1; cursor != ""; page++ {
+			if page >= maxItemsPages {
+				return nil, fmt.Errorf("the group '%s' on board '%s' has more than %d items, which is more than mgint can sync",
+					group.Title, board.Name, maxItemsPages*itemsPageLimit)
+			}
+
+			itemsPage, err := m.getNextItemsPage(ctx, cursor)
+			if err != nil {
+				return nil, fmt.Errorf("issue getting items for group '%s': %w", group.Title, err)
+			}
+
+			group.Items = append(group.Items, itemsPage.Items...)
+			cursor = itemsPage.Cursor
+		}
+	}
+
+	return board, nil
+}
+
+// GetItemBoard returns the board of an item, holding only the item and its group
+func (m *MondayClient) GetItemBoard(itemID string) (*Board, error) {
+	req := m.newRequest(`
+			query getItemBoard ($itemID: [ID!]) {
+			items(ids: $itemID) {` + itemFields + `
+				group {
+					id
+					title
+				}
+				board {
+					name
+					id
+					columns {
+						id
+						title
+						type
+					}
+				}
+			}
+			}
+			`)
+	req.Var("itemID", itemID)
+
+	var graphqlResponse struct {
+		Items []struct {
+			Item
+			Group Group `json:"group"`
+			Board Board `json:"board"`
+		} `json:"items"`
+	}
+	if err := m.run(context.Background(), req, &graphqlResponse); err != nil {
+		return nil, err
+	}
+
+	if len(graphqlResponse.Items) == 0 {
+		return nil, fmt.Errorf("an item with id %s does not exist", itemID)
+	}
+
+	response := graphqlResponse.Items[0]
+	board := response.Board
+	group := response.Group
+	group.Items = []Item{response.Item}
+	board.Groups = []Group{group}
+
+	return &board, nil
+}
+
+func (m *MondayClient) getNextItemsPage(ctx context.Context, cursor string) (*ItemsPage, error) {
+	req := m.newRequest(`
+			query getNextItemsPage ($cursor: String!, $limit: Int!) {
+			next_items_page(cursor: $cursor, limit: $limit) {
+				cursor
+				items {` + itemFields + `}
+			}
+			}
+			`)
+	req.Var("cursor", cursor)
+	req.Var("limit", itemsPageLimit)
+
+	var graphqlResponse struct {
+		NextItemsPage ItemsPage `json:"next_items_page"`
+	}
+	if err := m.run(ctx, req, &graphqlResponse); err != nil {
+		return nil, err
+	}
+
+	return &graphqlResponse.NextItemsPage, nil
+}
+
+// ListBoards returns every board the api key can see, with its columns and group titles but without items
+func (m *MondayClient) ListBoards() ([]Board, error) {
+	ctx := context.Background()
+
+	var boards []Board
+	for page := 1; ; page++ {
+		req := m.newRequest(`
+			query listBoards ($limit: Int!, $page: Int!) {
+			boards(limit: $limit, page: $page) {
+				name
+				id
+				items_count
+				workspace {
+					id
+					name
+				}
+				columns {
+					id
+					title
+					type
+				}
+				groups {
+					id
+					title
+				}
+			}
+			}
+			`)
+		req.Var("limit", boardsPageLimit)
+		req.Var("page", page)
+
+		var graphqlResponse Data
+		if err := m.run(ctx, req, &graphqlResponse); err != nil {
+			return nil, err
+		}
+
+		boards = append(boards, graphqlResponse.Boards...)
+
+		if len(graphqlResponse.Boards) < boardsPageLimit {
+			break
+		}
+	}
+
+	return boards, nil
+}
+
+// DuplicateBoard copies the groups and columns of a board, without its items, to a new board
+func (m *MondayClient) DuplicateBoard(boardID int, name string) (string, error) {
+	req := m.newRequest(`
+			mutation duplicateBoard ($boardID: ID!, $name: String!) {
+			duplicate_board(board_id: $boardID, board_name: $name, duplicate_type: duplicate_board_with_structure) {
+				board {
+					id
+				}
+			}
+			}
+			`)
+	req.Var("boardID", boardID)
+	req.Var("name", name)
+
+	var graphqlResponse struct {
+		DuplicateBoard struct {
+			Board struct {
+				ID string `json:"id"`
+			} `json:"board"`
+		} `json:"duplicate_board"`
+	}
+	if err := m.run(context.Background(), req, &graphqlResponse); err != nil {
+		return "", fmt.Errorf("issue duplicating board %d: %w", boardID, err)
+	}
+
+	return graphqlResponse.DuplicateBoard.Board.ID, nil
+}
+
+// CreateGroup adds a group to a board and returns its id
+func (m *MondayClient) CreateGroup(boardID string, name string) (string, error) {
+	req := m.newRequest(`
+			mutation createGroup ($boardID: ID!, $name: String!) {
+			create_group(board_id: $boardID, group_name: $name) {
+				id
+			}
+			}
+			`)
+	req.Var("boardID", boardID)
+	req.Var("name", name)
+
+	var graphqlResponse struct {
+		CreateGroup Group `json:"create_group"`
+	}
+	if err := m.run(context.Background(), req, &graphqlResponse); err != nil {
+		return "", fmt.Errorf("issue creating group '%s': %w", name, err)
+	}
+
+	return graphqlResponse.CreateGroup.ID, nil
+}
+
+// DeleteGroup removes a group and its items from a board
+func (m *MondayClient) DeleteGroup(boardID string, groupID string) error {
+	req := m.newRequest(`
+			mutation deleteGroup ($boardID: ID!, $groupID: String!) {
+			delete_group(board_id: $boardID, group_id: $groupID) {
+				id
+			}
+			}
+			`)
+	req.Var("boardID", boardID)
+	req.Var("groupID", groupID)
+
+	if err := m.run(context.Background(), req, nil); err != nil {
+		return fmt.Errorf("issue deleting group %s: %w", groupID, err)
+	}
+
+	return nil
+}
+
+// CreateColumn adds a column of columnType, e.g. numbers or date, to a board and returns its id
+func (m *MondayClient) CreateColumn(boardID string, title Title, columnType string) (ID, error) {
+	req := m.newRequest(`
+			mutation createColumn ($boardID: ID!, $title: String!, $columnType: ColumnType!) {
+			create_column(board_id: $boardID, title: $title, column_type: $columnType) {
+				id
+			}
+			}
+			`)
+	req.Var("boardID", boardID)
+	req.Var("title", title)
+	req.Var("columnType", columnType)
+
+	var graphqlResponse struct {
+		CreateColumn Column `json:"create_column"`
+	}
+	if err := m.run(context.Background(), req, &graphqlResponse); err != nil {
+		return "", fmt.Errorf("issue creating column '%s': %w", title, err)
+	}
+
+	return graphqlResponse.CreateColumn.ID, nil
+}
+
+// CreateItem adds an item to a group, columnValues is keyed by column id
+func (m *MondayClient) CreateItem(boardID string, groupID string, name string, columnValues map[ID]interface{}) (string, error) {
+	values, err := json.Marshal(columnValues)
+	if err != nil {
+		return "", fmt.Errorf("issue encoding column values for item '%s': %w", name, err)
+	}
+
+	req := m.newRequest(`
+			mutation createItem ($boardID: ID!, $groupID: String!, $name: String!, $columnValues: JSON!) {
+			create_item(board_id: $boardID, group_id: $groupID, item_name: $name, column_values: $columnValues) {
+				id
+			}
+			}
+			`)
+	req.Var("boardID", boardID)
+	req.Var("groupID", groupID)
+	req.Var("name", name)
+	req.Var("columnValues", string(values))
+
+	var graphqlResponse struct {
+		CreateItem Item `json:"create_item"`
+	}
+	if err := m.run(context.Background(), req, &graphqlResponse); err != nil {
+		return "", fmt.Errorf("issue creating item '%s': %w", name, err)
+	}
+
+	return graphqlResponse.CreateItem.ID, nil
+}
+
+// ChangeColumnValue sets one column of an item, value is encoded as the json the column type expects
+func (m *MondayClient) ChangeColumnValue(boardID string, itemID string, columnID ID, value interface{}) error {
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("issue encoding value for column %s: %w", columnID, err)
+	}
+
+	req := m.newRequest(`
+			mutation changeColumnValue ($boardID: ID!, $itemID: ID!, $columnID: String!, $value: JSON!) {
+			change_column_value(board_id: $boardID, item_id: $itemID, column_id: $columnID, value: $value) {
+				id
+			}
+			}
+			`)
+	req.Var("boardID", boardID)
+	req.Var("itemID", itemID)
+	req.Var("columnID", columnID)
+	req.Var("value", string(encodedValue))
+
+	if err := m.run(context.Background(), req, nil); err != nil {
+		return fmt.Errorf("issue changing column %s of item %s: %w", columnID, itemID, err)
+	}
+
+	return nil
+}
+
+// MoveItemToGroup moves an item to another group of its board
+func (m *MondayClient) MoveItemToGroup(itemID string, groupID string) error {
+	req := m.newRequest(`
+			mutation moveItemToGroup ($itemID: ID!, $groupID: String!) {
+			move_item_to_group(item_id: $itemID, group_id: $groupID) {
+				id
+			}
+			}
+			`)
+	req.Var("itemID", itemID)
+	req.Var("groupID", groupID)
+
+	if err := m.run(context.Background(), req, nil); err != nil {
+		return fmt.Errorf("issue moving item %s to group %s: %w", itemID, groupID, err)
+	}
+
+	return nil
+}
+
+// run sends a request to Monday.com, failures are returned as a MondayAPIError or AuthError
+func (m *MondayClient) run(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	if err := m.Client.Run(ctx, req, resp); err != nil {
+		return mondayError(err)
+	}
+	return nil
+}
+
+func (m *MondayClient) newRequest(query string) *graphql.Request {
+	req := graphql.NewRequest(query)
+	req.Header.Set("Authorization", m.APIKey)
+	req.Header.Set("API-Version", mondayAPIVersion)
+	req.Header.Set("Cache-Control", "no-cache")
+	return req
 }
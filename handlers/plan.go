@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+type ChangeAction string
+
+const (
+	ActionAdd    ChangeAction = "add"
+	ActionUpdate ChangeAction = "update"
+	ActionDelete ChangeAction = "delete"
+)
+
+// SyncPlan is the list of event changes needed for a calendar to reflect a board.
+// Snapshot holds the ETag of every event seen between TimeMin and TimeMax while planning,
+// so a saved plan can tell if the calendar changed before it is applied.
+type SyncPlan struct {
+	BoardID         string `json:"boardID"`
+	BoardName       string `json:"boardName"`
+	CalendarID      string `json:"calendarID"`
+	CalendarSummary string `json:"calendarSummary"`
+	// CalendarTimeZone is the time zone to create the calendar in when CalendarID is empty
+	CalendarTimeZone string            `json:"calendarTimeZone,omitempty"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	WeekStart        time.Time         `json:"weekStart"`
+	TimeMin          time.Time         `json:"timeMin"`
+	TimeMax          time.Time         `json:"timeMax"`
+	Snapshot         map[string]string `json:"snapshot"`
+	Changes          []*EventChange    `json:"changes"`
+	MondayChanges    []*MondayChange   `json:"mondayChanges,omitempty"`
+	ItemFixes        []*ItemFix        `json:"itemFixes,omitempty"`
+	InSync           []*SyncedItem     `json:"inSync,omitempty"`
+	Skipped          int               `json:"skipped"`
+	KeepGoing        bool              `json:"keepGoing,omitempty"`
+	Failures         []*ItemFailure    `json:"failures,omitempty"`
+}
+
+// EventChange is a single planned change. Old is the event currently on the calendar
+// and New is what it will look like, either can be nil for adds and deletes.
+type EventChange struct {
+	Action    ChangeAction    `json:"action"`
+	ItemID    string          `json:"itemID,omitempty"`
+	TaskName  string          `json:"taskName"`
+	UpdatedAt string          `json:"updatedAt,omitempty"`
+	Group     string          `json:"group,omitempty"`
+	Day       time.Time       `json:"day"`
+	Old       *calendar.Event `json:"old,omitempty"`
+	New       *calendar.Event `json:"new,omitempty"`
+	// WriteBack updates only record the event's times as synced, they depend on the
+	// item's MondayChange being written first
+	WriteBack bool `json:"writeBack,omitempty"`
+}
+
+// SyncedItem is an item whose event already matches it, recorded in the state when the plan is applied
+type SyncedItem struct {
+	ItemID    string `json:"itemID"`
+	EventID   string `json:"eventID"`
+	UpdatedAt string `json:"updatedAt"`
+	ETag      string `json:"etag"`
+}
+
+// ItemFailure is an item that could not be synced, collected instead of stopping the sync when KeepGoing is set
+type ItemFailure struct {
+	ItemID   string `json:"itemID"`
+	ItemName string `json:"itemName"`
+	Group    string `json:"group"`
+	Reason   string `json:"reason"`
+}
+
+// ReadSyncPlan loads a plan written by WriteFile
+func ReadSyncPlan(filename string) (*SyncPlan, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("issue reading plan file %s: %w", filename, err)
+	}
+
+	plan := &SyncPlan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("issue decoding plan file %s: %w", filename, err)
+	}
+
+	return plan, nil
+}
+
+// WriteFile saves the plan as json so it can be reviewed and applied later
+func (p *SyncPlan) WriteFile(filename string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("issue encoding plan: %w", err)
+	}
+
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("issue writing plan file %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// skipTask keeps an item that has not changed since it was last synced out of the plan
+func (p *SyncPlan) skipTask(task *Item, event *calendar.Event) {
+	p.Skipped++
+	p.InSync = append(p.InSync, &SyncedItem{
+		ItemID:    task.ID,
+		EventID:   event.Id,
+		UpdatedAt: task.UpdatedAt,
+		ETag:      event.Etag,
+	})
+}
+
+// GroupOrDay is the group of the item changed, or the day of the event for deletes which have no group
+func (c *EventChange) GroupOrDay() string {
+	if c.Group != "" {
+		return c.Group
+	}
+	return c.Day.Format("Mon 2006-01-02")
+}
+
+// addFailure records an item that could not be synced
+func (p *SyncPlan) addFailure(itemID string, itemName string, group string, err error) {
+	// the item is already named in the failure, only keep why it failed
+	reason := err
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		reason = validationErr.Err
+	}
+
+	p.Failures = append(p.Failures, &ItemFailure{
+		ItemID:   itemID,
+		ItemName: itemName,
+		Group:    group,
+		Reason:   reason.Error(),
+	})
+}
+
+// FailuresError returns a SyncFailuresError if any item failed to sync
+func (p *SyncPlan) FailuresError() error {
+	if len(p.Failures) == 0 {
+		return nil
+	}
+	return &SyncFailuresError{Failures: p.Failures}
+}
+
+// PrintFailures writes a summary of the items that failed to sync
+func (p *SyncPlan) PrintFailures(w io.Writer) {
+	if len(p.Failures) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n%d item(s) of board '%s' failed to sync:\n", len(p.Failures), p.BoardName)
+	for _, failure := range p.Failures {
+		fmt.Fprintf(w, "! %s '%s' (%s): %s\n", failure.Group, failure.ItemName, failure.ItemID, failure.Reason)
+	}
+}
+
+// Count returns how many changes of the given action are in the plan
+func (p *SyncPlan) Count(action ChangeAction) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Print writes the plan as a readable diff
+func (p *SyncPlan) Print(w io.Writer) {
+	fmt.Fprintf(w, "Plan for board '%s' on calendar '%s', week of %s: %d to add, %d to update, %d to delete\n",
+		p.BoardName, p.CalendarSummary, p.WeekStart.Format(WeekOfDateFormat),
+		p.Count(ActionAdd), p.Count(ActionUpdate), p.Count(ActionDelete))
+	if len(p.MondayChanges) > 0 {
+		fmt.Fprintf(w, "%d Monday.com item(s) to update from the calendar\n", len(p.MondayChanges))
+	}
+	if len(p.ItemFixes) > 0 {
+		fmt.Fprintf(w, "%d Monday.com item(s) to fix\n", len(p.ItemFixes))
+	}
+	if p.Skipped > 0 {
+		fmt.Fprintf(w, "%d item(s) unchanged since the last sync were skipped\n", p.Skipped)
+	}
+
+	if len(p.Changes) == 0 && len(p.MondayChanges) == 0 && len(p.ItemFixes) == 0 {
+		fmt.Fprintln(w, "No changes. The calendar is up to date.")
+		p.PrintFailures(w)
+		return
+	}
+
+	for _, fix := range p.ItemFixes {
+		fmt.Fprintln(w)
+		switch fix.Mode {
+		case FixMoveItem:
+			fmt.Fprintf(w, "> '%s' (Monday.com)\n", fix.TaskName)
+			printDiffLine(w, "group", fix.FromGroup, fix.ToGroup)
+			fmt.Fprintf(w, "    %-7s %s\n", "due:", fix.DueDate.Format("Mon 2006-01-02 15:04"))
+		case FixClearDueDate:
+			fmt.Fprintf(w, "> '%s' in group '%s' (Monday.com)\n", fix.TaskName, fix.FromGroup)
+			printDiffLine(w, "due", fix.DueDate.Format("Mon 2006-01-02 15:04"), "")
+		}
+	}
+
+	for _, change := range p.Changes {
+		fmt.Fprintln(w)
+
+		switch change.Action {
+		case ActionAdd:
+			fmt.Fprintf(w, "+ %s '%s'\n", change.Day.Format("Mon 2006-01-02"), change.TaskName)
+			fmt.Fprintf(w, "    start:  %s\n", eventTimeString(change.New.Start))
+			fmt.Fprintf(w, "    end:    %s\n", eventTimeString(change.New.End))
+			fmt.Fprintf(w, "    status: %s\n", change.New.Status)
+			if change.New.ColorId != "" || change.New.Transparency != "" {
+				fmt.Fprintf(w, "    style:  %s\n", eventStyleString(change.New))
+			}
+		case ActionDelete:
+			fmt.Fprintf(w, "- %s '%s'\n", change.Day.Format("Mon 2006-01-02"), change.TaskName)
+			fmt.Fprintf(w, "    start:  %s\n", eventTimeString(change.Old.Start))
+			fmt.Fprintf(w, "    end:    %s\n", eventTimeString(change.Old.End))
+			fmt.Fprintf(w, "    status: %s\n", change.Old.Status)
+		case ActionUpdate:
+			fmt.Fprintf(w, "~ %s '%s'\n", change.Day.Format("Mon 2006-01-02"), change.TaskName)
+			printDiffLine(w, "name", change.Old.Summary, change.New.Summary)
+			printDiffLine(w, "start", eventTimeString(change.Old.Start), eventTimeString(change.New.Start))
+			printDiffLine(w, "end", eventTimeString(change.Old.End), eventTimeString(change.New.End))
+			printDiffLine(w, "status", change.Old.Status, change.New.Status)
+			if oldStyle, newStyle := eventStyleString(change.Old), eventStyleString(change.New); oldStyle != "" || newStyle != "" {
+				printDiffLine(w, "style", oldStyle, newStyle)
+			}
+		}
+	}
+
+	for _, change := range p.MondayChanges {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "< %s '%s' (Monday.com)\n", change.Day.Format("Mon 2006-01-02"), change.TaskName)
+		printDiffLine(w, "due", change.OldDueDate.Format("Mon 2006-01-02 15:04"), change.NewDueDate.Format("Mon 2006-01-02 15:04"))
+		printDiffLine(w, "hours", strconv.FormatFloat(change.OldEstimate.Hours(), 'f', -1, 64), strconv.FormatFloat(change.NewEstimate.Hours(), 'f', -1, 64))
+	}
+
+	p.PrintFailures(w)
+}
+
+func printDiffLine(w io.Writer, label string, oldValue string, newValue string) {
+	if oldValue == newValue {
+		fmt.Fprintf(w, "    %-7s %s\n", label+":", newValue)
+		return
+	}
+	fmt.Fprintf(w, "    %-7s %s -> %s\n", label+":", oldValue, newValue)
+}
+
+func eventTimeString(eventTime *calendar.EventDateTime) string {
+	if eventTime == nil {
+		return ""
+	}
+	if eventTime.DateTime == "" {
+		return eventTime.Date
+	}
+
+	t, err := time.Parse(time.RFC3339, eventTime.DateTime)
+	if err != nil {
+		return eventTime.DateTime
+	}
+	return t.Format("Mon 2006-01-02 15:04")
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const stateFileName = "mgint-state.json"
+
+// State is kept between runs in the user cache dir, next to the cached oauth token
+type State struct {
+	Boards    map[string]*BoardState    `json:"boards"`
+	Calendars map[string]*CalendarState `json:"calendars"`
+
+	mu   sync.Mutex
+	path string
+}
+
+// BoardState maps a board to its calendar and its items to their events
+type BoardState struct {
+	CalendarID string                `json:"calendarID"`
+	Items      map[string]*ItemState `json:"items"`
+}
+
+// ItemState is an item as of its last sync
+type ItemState struct {
+	EventID   string `json:"eventID"`
+	UpdatedAt string `json:"updatedAt"`
+	ETag      string `json:"etag"`
+}
+
+// CalendarState holds the events of a calendar as of its SyncToken,
+// so later runs only have to ask google for what changed since
+type CalendarState struct {
+	SyncToken string                     `json:"syncToken"`
+	Events    map[string]*calendar.Event `json:"events"`
+}
+
+// StatePath is where the state is saved
+func StatePath() string {
+	return filepath.Join(osUserCacheDir(), stateFileName)
+}
+
+// LoadState reads the state file, a missing file is an empty state
+func LoadState(path string) (*State, error) {
+	state := &State{
+		Boards:    make(map[string]*BoardState),
+		Calendars: make(map[string]*CalendarState),
+		path:      path,
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("issue reading state file %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return state, fmt.Errorf("issue decoding state file %s: %w", path, err)
+	}
+	state.init()
+
+	return state, nil
+}
+
+func (s *State) init() {
+	if s.Boards == nil {
+		s.Boards = make(map[string]*BoardState)
+	}
+	for _, boardState := range s.Boards {
+		if boardState.Items == nil {
+			boardState.Items = make(map[string]*ItemState)
+		}
+	}
+	if s.Calendars == nil {
+		s.Calendars = make(map[string]*CalendarState)
+	}
+	for _, calendarState := range s.Calendars {
+		if calendarState.Events == nil {
+			calendarState.Events = make(map[string]*calendar.Event)
+		}
+	}
+}
+
+// ImportState replaces the state file at path with the state read from r
+func ImportState(path string, r io.Reader) (*State, error) {
+	state := &State{path: path}
+	if err := json.NewDecoder(r).Decode(state); err != nil {
+		return nil, fmt.Errorf("issue decoding state: %w", err)
+	}
+	state.init()
+
+	if err := state.Save(); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// ResetState removes the state file, the next sync reads everything from the apis again
+func ResetState(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("issue removing state file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Export writes the state as indented json
+func (s *State) Export(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("issue encoding state: %w", err)
+	}
+	return nil
+}
+
+// ResetBoard forgets a board, its items and the events of its calendar
+func (s *State) ResetBoard(boardID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if boardState, ok := s.Boards[boardID]; ok {
+		delete(s.Calendars, boardState.CalendarID)
+	}
+	delete(s.Boards, boardID)
+}
+
+// BoardCalendarID returns the id of the calendar last used for a board
+func (s *State) BoardCalendarID(boardID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if boardState, ok := s.Boards[boardID]; ok {
+		return boardState.CalendarID
+	}
+	return ""
+}
+
+// SetBoardCalendarID records the calendar of a board
+func (s *State) SetBoardCalendarID(boardID string, calendarID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.board(boardID).CalendarID = calendarID
+}
+
+// Item returns an item as of its last sync
+func (s *State) Item(boardID string, itemID string) (ItemState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	itemState, ok := s.board(boardID).Items[itemID]
+	if !ok {
+		return ItemState{}, false
+	}
+	return *itemState, true
+}
+
+// SetItem records an item after it was synced
+func (s *State) SetItem(boardID string, itemID string, itemState ItemState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.board(boardID).Items[itemID] = &itemState
+}
+
+// DeleteItem forgets an item whose event was deleted
+func (s *State) DeleteItem(boardID string, itemID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.board(boardID).Items, itemID)
+}
+
+func (s *State) board(boardID string) *BoardState {
+	boardState, ok := s.Boards[boardID]
+	if !ok {
+		boardState = &BoardState{
+			Items: make(map[string]*ItemState),
+		}
+		s.Boards[boardID] = boardState
+	}
+	return boardState
+}
+
+// Save writes the state file, a state without a path is only kept in memory
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.save()
+}
+
+func (s *State) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("issue encoding state: %w", err)
+	}
+
+	// write to a temporary file first so a crash can't leave half a state file behind
+	tmpPath := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("issue writing state file %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("issue replacing state file %s: %w", s.path, err)
+	}
+
+	return nil
+}
+
+func (s *State) calendar(calendarID string) *CalendarState {
+	calendarState, ok := s.Calendars[calendarID]
+	if !ok {
+		calendarState = &CalendarState{
+			Events: make(map[string]*calendar.Event),
+		}
+		s.Calendars[calendarID] = calendarState
+	}
+	return calendarState
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// the google calendar event colors by the names the calendar shows them with
+var eventColorIDs = map[string]string{
+	"lavender":  "1",
+	"sage":      "2",
+	"grape":     "3",
+	"flamingo":  "4",
+	"banana":    "5",
+	"tangerine": "6",
+	"peacock":   "7",
+	"graphite":  "8",
+	"blueberry": "9",
+	"basil":     "10",
+	"tomato":    "11",
+}
+
+// StatusStyle is how the event of an item with a status label is shown
+type StatusStyle struct {
+	// Color is the event colorId, empty for the calendar's color
+	Color string
+	// Transparent events don't block time in free/busy
+	Transparent bool
+	// Cancelled events are removed from the calendar
+	Cancelled bool
+}
+
+// StatusMapping is the style of every status label that has one, labels are lower case
+type StatusMapping map[string]StatusStyle
+
+// ParseEventColor validates a color name or colorId from the config
+func ParseEventColor(value string) (string, error) {
+	if id, ok := eventColorIDs[strings.ToLower(value)]; ok {
+		return id, nil
+	}
+	for _, id := range eventColorIDs {
+		if id == value {
+			return id, nil
+		}
+	}
+
+	names := make([]string, 0, len(eventColorIDs))
+	for name := range eventColorIDs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("'%s' is not an event color, use a colorId from 1 to 11 or one of %s", value, strings.Join(names, ", "))
+}
+
+// itemStatusStyle returns the style of an item's status label, the zero style when it has none
+func (b *Board) itemStatusStyle(task *Item) (StatusStyle, error) {
+	if len(b.StatusStyles) == 0 {
+		return StatusStyle{}, nil
+	}
+
+	status, ok, err := b.itemValue(task, RoleStatus).Status()
+	if err != nil {
+		return StatusStyle{}, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue reading status: %w", err)}
+	}
+	if !ok {
+		return StatusStyle{}, nil
+	}
+
+	return b.StatusStyles[strings.ToLower(status.Label)], nil
+}
+
+// apply sets the color, transparency and status of an event made by taskToEvent
+func (s StatusStyle) apply(event *calendar.Event) {
+	event.ColorId = s.Color
+	if s.Transparent {
+		event.Transparency = "transparent"
+	}
+	if s.Cancelled {
+		event.Status = "cancelled"
+	}
+}
+
+// matches reports whether an event already has the style, events without a transparency are opaque
+func (s StatusStyle) matches(event *calendar.Event) bool {
+	return event.ColorId == s.Color &&
+		(event.Transparency == "transparent") == s.Transparent &&
+		(event.Status == "cancelled") == s.Cancelled
+}
+
+// eventStyleEqual reports whether two events have the same color, transparency and cancelled status
+func eventStyleEqual(a *calendar.Event, b *calendar.Event) bool {
+	style := StatusStyle{
+		Color:       b.ColorId,
+		Transparent: b.Transparency == "transparent",
+		Cancelled:   b.Status == "cancelled",
+	}
+	return style.matches(a)
+}
+
+// eventStyleString describes the color and transparency of an event for printing, empty when neither is set
+func eventStyleString(event *calendar.Event) string {
+	var style []string
+	if event.ColorId != "" {
+		color := event.ColorId
+		for name, id := range eventColorIDs {
+			if id == event.ColorId {
+				color = name
+			}
+		}
+		style = append(style, color)
+	}
+	if event.Transparency == "transparent" {
+		style = append(style, "free")
+	}
+	return strings.Join(style, ", ")
+}
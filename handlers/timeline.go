@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// timelineItem is an item placed on the calendar by its timeline column instead of its group,
+// from and to are midnight of its first and last day
+type timelineItem struct {
+	group *Group
+	task  *Item
+	from  time.Time
+	to    time.Time
+}
+
+// timelineTaskToEvent makes the event of an item with a timeline. Without a due date time it is an
+// all-day event over every day of the timeline, with one it is a timed event ending at that time on
+// the last day and starting the estimate before that time on the first day.
+func timelineTaskToEvent(board *Board, task *Item, from time.Time, to time.Time, loc *time.Location) (*calendar.Event, error) {
+	dueDate, dueDateHasTime, err := board.itemDueDate(task, loc)
+	if err != nil {
+		return nil, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue parsing DueDateAndTime: %w", err)}
+	}
+
+	var start, end time.Time
+	event := &calendar.Event{
+		Description: "Created by cli tool",
+		Summary:     task.Name,
+		Status:      "confirmed",
+	}
+
+	if dueDateHasTime {
+		estimate, err := board.itemEstimate(task)
+		if err != nil {
+			return nil, &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue converting EstimateHours: %w", err)}
+		}
+
+		end = time.Date(to.Year(), to.Month(), to.Day(), dueDate.Hour(), dueDate.Minute(), 0, 0, loc)
+		start = time.Date(from.Year(), from.Month(), from.Day(), dueDate.Hour(), dueDate.Minute(), 0, 0, loc).Add(-estimate)
+		if !start.Before(end) {
+			return nil, &ValidationError{ItemID: task.ID, ItemName: task.Name,
+				Err: fmt.Errorf("the timeline %s to %s ends before it starts", start.Format("Mon 2006-01-02 15:04"), end.Format("Mon 2006-01-02 15:04"))}
+		}
+
+		event.Start = &calendar.EventDateTime{DateTime: start.Format(time.RFC3339), TimeZone: loc.String()}
+		event.End = &calendar.EventDateTime{DateTime: end.Format(time.RFC3339), TimeZone: loc.String()}
+	} else {
+		// the end date of an all-day event is the day after its last day
+		start = from
+		end = to.AddDate(0, 0, 1)
+
+		event.Start = &calendar.EventDateTime{Date: dayKey(start)}
+		event.End = &calendar.EventDateTime{Date: dayKey(end)}
+	}
+
+	event.ExtendedProperties = &calendar.EventExtendedProperties{
+		Private: map[string]string{
+			ItemIDProperty:      task.ID,
+			BoardIDProperty:     board.ID,
+			CreatedByProperty:   CreatedByValue,
+			SyncedStartProperty: start.Format(time.RFC3339),
+			SyncedEndProperty:   end.Format(time.RFC3339),
+		},
+	}
+
+	style, err := board.itemStatusStyle(task)
+	if err != nil {
+		return nil, err
+	}
+	style.apply(event)
+
+	return event, nil
+}
+
+// planTimelineTask adds the change needed for the event of an item with a timeline to match it,
+// event is nil when the item has no event yet
+func (p *SyncPlan) planTimelineTask(board *Board, item *timelineItem, event *calendar.Event, loc *time.Location) error {
+	timelineEvent, err := timelineTaskToEvent(board, item.task, item.from, item.to, loc)
+	if err != nil {
+		return err
+	}
+
+	change := &EventChange{
+		ItemID:    item.task.ID,
+		TaskName:  item.task.Name,
+		UpdatedAt: item.task.UpdatedAt,
+		Group:     item.group.Title,
+		Day:       item.from,
+		New:       timelineEvent,
+	}
+
+	if event == nil {
+		// a cancelled event is removed, there is nothing to add
+		if timelineEvent.Status == "cancelled" {
+			return nil
+		}
+		change.Action = ActionAdd
+		p.Changes = append(p.Changes, change)
+		return nil
+	}
+
+	if event.Summary == timelineEvent.Summary && eventItemID(event) == item.task.ID && eventStyleEqual(event, timelineEvent) &&
+		eventDateTimesEqual(event.Start, timelineEvent.Start) && eventDateTimesEqual(event.End, timelineEvent.End) {
+		p.InSync = append(p.InSync, &SyncedItem{
+			ItemID:    item.task.ID,
+			EventID:   event.Id,
+			UpdatedAt: item.task.UpdatedAt,
+			ETag:      event.Etag,
+		})
+		return nil
+	}
+
+	timelineEvent.Id = event.Id
+	change.Action = ActionUpdate
+	change.Old = event
+	p.Changes = append(p.Changes, change)
+	return nil
+}
+
+// eventDateTimesEqual reports whether two event times are the same day, or the same instant for timed events
+func eventDateTimesEqual(a *calendar.EventDateTime, b *calendar.EventDateTime) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.DateTime == "" || b.DateTime == "" {
+		return a.DateTime == b.DateTime && a.Date == b.Date
+	}
+
+	aTime, err := time.Parse(time.RFC3339, a.DateTime)
+	if err != nil {
+		return false
+	}
+	bTime, err := time.Parse(time.RFC3339, b.DateTime)
+	if err != nil {
+		return false
+	}
+	return aTime.Equal(bTime)
+}
+
+// eventAllDay reports whether an event covers whole days rather than a time range
+func eventAllDay(event *calendar.Event) bool {
+	return event.Start != nil && event.Start.DateTime == ""
+}
+
+// boardTimelineItems returns the items of the day groups that have a timeline, and the ids of those items
+// and of items whose timeline could not be read, so neither is synced as an event on its group's day.
+// The plan's time range is widened to cover every timeline.
+func (p *SyncPlan) boardTimelineItems(board *Board, groupDays map[string]time.Time, loc *time.Location, opts SyncOptions) ([]*timelineItem, map[string]bool, error) {
+	var items []*timelineItem
+	itemIDs := make(map[string]bool)
+
+	for i := range board.Groups {
+		group := &board.Groups[i]
+		if _, ok := groupDays[group.ID]; !ok {
+			continue
+		}
+
+		for j := range group.Items {
+			task := &group.Items[j]
+
+			from, to, ok, err := board.itemTimeline(task, loc)
+			if err != nil {
+				err = &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf("issue parsing timeline: %w", err)}
+				if !opts.KeepGoing {
+					return nil, nil, err
+				}
+				p.addFailure(task.ID, task.Name, group.Title, err)
+				itemIDs[task.ID] = true
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			items = append(items, &timelineItem{group: group, task: task, from: from, to: to})
+			itemIDs[task.ID] = true
+			p.coverDays(from, to)
+		}
+	}
+
+	return items, itemIDs, nil
+}
+
+// coverWeek widens the plan's time range to cover every day of the week being synced
+func (p *SyncPlan) coverWeek() {
+	p.coverDays(p.WeekStart, p.WeekStart.AddDate(0, 0, 6))
+}
+
+// coverDays widens the plan's time range to cover every day from from to to
+func (p *SyncPlan) coverDays(from time.Time, to time.Time) {
+	if p.TimeMin.IsZero() || from.Before(p.TimeMin) {
+		p.TimeMin = from
+	}
+	if endOfDay := to.AddDate(0, 0, 1).Add(-time.Minute); endOfDay.After(p.TimeMax) {
+		p.TimeMax = endOfDay
+	}
+}
+
+// dayItems returns the items that are synced as an event on their group's day
+func dayItems(items []Item, timelineItemIDs map[string]bool) []Item {
+	if len(timelineItemIDs) == 0 {
+		return items
+	}
+
+	dayItems := make([]Item, 0, len(items))
+	for _, item := range items {
+		if !timelineItemIDs[item.ID] {
+			dayItems = append(dayItems, item)
+		}
+	}
+	return dayItems
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+type ConflictWinner string
+
+const (
+	// WinnerMonday keeps the Monday.com item when both sides changed, this is the default
+	WinnerMonday ConflictWinner = "monday"
+	// WinnerCalendar writes the calendar event back to Monday.com when both sides changed
+	WinnerCalendar ConflictWinner = "calendar"
+
+	// private extended properties holding the event times as of the last sync,
+	// used to tell which side changed since then
+	SyncedStartProperty = "mgintSyncedStart"
+	SyncedEndProperty   = "mgintSyncedEnd"
+)
+
+// ParseConflictWinner validates a conflict rule, an empty value means WinnerMonday
+func ParseConflictWinner(value string) (ConflictWinner, error) {
+	switch ConflictWinner(value) {
+	case "", WinnerMonday:
+		return WinnerMonday, nil
+	case WinnerCalendar:
+		return WinnerCalendar, nil
+	}
+	return "", fmt.Errorf("'%s' is not a valid conflict winner, use '%s' or '%s'", value, WinnerMonday, WinnerCalendar)
+}
+
+// MondayChange is a planned write of a calendar event's end time and length
+// back to the item's due date and estimate
+type MondayChange struct {
+	ItemID           string        `json:"itemID"`
+	TaskName         string        `json:"taskName"`
+	Day              time.Time     `json:"day"`
+	DueDateColumnID  ID            `json:"dueDateColumnID"`
+	EstimateColumnID ID            `json:"estimateColumnID"`
+	OldDueDate       time.Time     `json:"oldDueDate"`
+	NewDueDate       time.Time     `json:"newDueDate"`
+	OldEstimate      time.Duration `json:"oldEstimate"`
+	NewEstimate      time.Duration `json:"newEstimate"`
+}
+
+// planCalendarWriteBack checks if an event was changed on the calendar since the last sync. When it was,
+// and the item did not change too or the calendar wins conflicts, it returns the change to make on Monday.com
+// and the event with its synced times brought up to date.
+func planCalendarWriteBack(board *Board, task *Item, event *calendar.Event, mondayEvent *calendar.Event, winner ConflictWinner) (*MondayChange, *calendar.Event, error) {
+	syncedStart, syncedEnd, ok := eventSyncedTimes(event)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	eventStart, eventEnd, err := eventTimes(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	mondayStart, mondayEnd, err := eventTimes(mondayEvent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	calendarChanged := !eventStart.Equal(syncedStart) || !eventEnd.Equal(syncedEnd)
+	mondayChanged := !mondayStart.Equal(syncedStart) || !mondayEnd.Equal(syncedEnd)
+
+	if !calendarChanged {
+		return nil, nil, nil
+	}
+	if mondayChanged && winner != WinnerCalendar {
+		log.Printf("'%s' changed on both Monday.com and the calendar, keeping the Monday.com item", task.Name)
+		return nil, nil, nil
+	}
+
+	change := &MondayChange{
+		ItemID:      task.ID,
+		TaskName:    task.Name,
+		OldDueDate:  mondayEnd,
+		NewDueDate:  eventEnd,
+		OldEstimate: mondayEnd.Sub(mondayStart),
+		NewEstimate: eventEnd.Sub(eventStart),
+	}
+
+	dueDateColumn, hasDueDate := board.column(RoleDueDate)
+	estimateColumn, hasEstimate := board.column(RoleEstimate)
+	if !hasDueDate || !hasEstimate {
+		return nil, nil, fmt.Errorf("board '%s' needs a due date and an estimate column to write calendar changes back", board.Name)
+	}
+	change.DueDateColumnID = dueDateColumn.ID
+	change.EstimateColumnID = estimateColumn.ID
+
+	// keep the calendar times, but remember them as synced and take the name from Monday.com
+	updatedEvent := *event
+	updatedEvent.Summary = task.Name
+	updatedEvent.ExtendedProperties = &calendar.EventExtendedProperties{
+		Private: map[string]string{},
+	}
+	for key, value := range event.ExtendedProperties.Private {
+		updatedEvent.ExtendedProperties.Private[key] = value
+	}
+	updatedEvent.ExtendedProperties.Private[SyncedStartProperty] = eventStart.Format(time.RFC3339)
+	updatedEvent.ExtendedProperties.Private[SyncedEndProperty] = eventEnd.Format(time.RFC3339)
+
+	return change, &updatedEvent, nil
+}
+
+// eventSyncedTimes returns the start and end an event had when it was last synced
+func eventSyncedTimes(event *calendar.Event) (time.Time, time.Time, bool) {
+	if event.ExtendedProperties == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, err := time.Parse(time.RFC3339, event.ExtendedProperties.Private[SyncedStartProperty])
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	end, err := time.Parse(time.RFC3339, event.ExtendedProperties.Private[SyncedEndProperty])
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, end, true
+}
+
+func eventTimes(event *calendar.Event) (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, event.Start.DateTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("issue parsing event start datetime: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, event.End.DateTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("issue parsing event end datetime: %w", err)
+	}
+	return start, end, nil
+}
+
+// planDraggedItem plans moving an item to the group of the day its event was dragged to on the calendar.
+// Items are only moved with --fix=move-item, otherwise the drag is reported and the event is left alone.
+func (p *SyncPlan) planDraggedItem(board *Board, group *Group, task *Item, newDay time.Time, loc *time.Location, mode FixMode) error {
+	if mode != FixMoveItem {
+		return &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf(
+			"the event was moved to %s on the calendar, move the item to that day's group on Monday.com or sync with --fix=%s",
+			newDay.Format("Monday 2006-01-02"), FixMoveItem)}
+	}
+
+	for _, to := range board.Groups {
+		day, ok := groupDay(to.Title, p.WeekStart, loc)
+		if !ok || dayKey(day) != dayKey(newDay) {
+			continue
+		}
+
+		log.Printf("planning to move '%s' from group '%s' to '%s', its event was moved on the calendar", task.Name, group.Title, to.Title)
+		p.ItemFixes = append(p.ItemFixes, &ItemFix{
+			Mode:        FixMoveItem,
+			ItemID:      task.ID,
+			TaskName:    task.Name,
+			FromGroupID: group.ID,
+			FromGroup:   group.Title,
+			ToGroupID:   to.ID,
+			ToGroup:     to.Title,
+			DueDate:     newDay,
+		})
+		return nil
+	}
+
+	return &ValidationError{ItemID: task.ID, ItemName: task.Name, Err: fmt.Errorf(
+		"the event was moved to %s on the calendar, which the board has no group for", newDay.Format("Monday 2006-01-02"))}
+}
+
+// ApplySyncPlan writes the calendar changes of a plan back to the Monday.com items. It runs before the
+// calendar changes, so an event's times are only recorded as synced once they were written to its item.
+func (m *MondayClient) ApplySyncPlan(plan *SyncPlan) error {
+	for i, change := range plan.MondayChanges {
+		// date column values are stored in UTC
+		dueDate := change.NewDueDate.UTC()
+		err := m.ChangeColumnValue(plan.BoardID, change.ItemID, change.DueDateColumnID, map[string]string{
+			"date": dueDate.Format("2006-01-02"),
+			"time": dueDate.Format("15:04:05"),
+		})
+		if err != nil {
+			if !plan.KeepGoing {
+				return partialSyncError(i, len(plan.MondayChanges), err)
+			}
+			plan.addFailure(change.ItemID, change.TaskName, change.Day.Format("Mon 2006-01-02"), err)
+			plan.dropWriteBack(change.ItemID)
+			continue
+		}
+
+		estimate := strconv.FormatFloat(change.NewEstimate.Hours(), 'f', -1, 64)
+		if err := m.ChangeColumnValue(plan.BoardID, change.ItemID, change.EstimateColumnID, estimate); err != nil {
+			if !plan.KeepGoing {
+				// the due date of this item was already written
+				return &PartialSyncError{Applied: i, Total: len(plan.MondayChanges), Err: err}
+			}
+			plan.addFailure(change.ItemID, change.TaskName, change.Day.Format("Mon 2006-01-02"), err)
+			plan.dropWriteBack(change.ItemID)
+		}
+	}
+
+	return nil
+}
+
+// dropWriteBack removes the write back update of an item whose MondayChange failed, so its event
+// keeps the old synced times and the calendar edit is written back again by the next sync
+func (p *SyncPlan) dropWriteBack(itemID string) {
+	changes := p.Changes[:0]
+	for _, change := range p.Changes {
+		if change.WriteBack && change.ItemID == itemID {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	p.Changes = changes
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DateValue is the value of a date column. The time is in UTC and empty for dates without a time.
+type DateValue struct {
+	Date string `json:"date"`
+	Time string `json:"time"`
+}
+
+// TimelineValue is the value of a timeline column, the first and last day it covers
+type TimelineValue struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// StatusValue is the value of a status column, the label is read from the column's text
+type StatusValue struct {
+	Index *int   `json:"index"`
+	Label string `json:"label"`
+}
+
+// PeopleValue is the value of a people column
+type PeopleValue struct {
+	PersonsAndTeams []PersonOrTeam `json:"personsAndTeams"`
+}
+
+type PersonOrTeam struct {
+	ID   int64  `json:"id"`
+	Kind string `json:"kind"`
+}
+
+// In returns the date and time in loc, and whether the date has a time.
+// A date without a time is midnight of that day in loc.
+func (v DateValue) In(loc *time.Location) (time.Time, bool, error) {
+	if v.Time == "" {
+		day, err := time.ParseInLocation(WeekOfDateFormat, v.Date, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("issue parsing date '%s': %w", v.Date, err)
+		}
+		return day, false, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", v.Date+" "+v.Time, time.UTC)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("issue parsing date and time '%s %s': %w", v.Date, v.Time, err)
+	}
+	return t.In(loc), true, nil
+}
+
+// Days returns the first and last day of the timeline at midnight in loc
+func (v TimelineValue) Days(loc *time.Location) (time.Time, time.Time, error) {
+	from, err := time.ParseInLocation(WeekOfDateFormat, v.From, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("issue parsing timeline start '%s': %w", v.From, err)
+	}
+	to, err := time.ParseInLocation(WeekOfDateFormat, v.To, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("issue parsing timeline end '%s': %w", v.To, err)
+	}
+	return from, to, nil
+}
+
+// IsEmpty reports whether the column has no value for the item
+func (c *ColumnValue) IsEmpty() bool {
+	if c == nil || c.Value == nil {
+		return true
+	}
+	switch *c.Value {
+	case "", "null", "{}", `""`:
+		return true
+	}
+	return false
+}
+
+// decode reads the value json into v, it returns false if the column is empty
+func (c *ColumnValue) decode(v interface{}) (bool, error) {
+	if c.IsEmpty() {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(*c.Value), v); err != nil {
+		return false, fmt.Errorf("issue decoding the %s value of column %s: %w", c.Type, c.ID, err)
+	}
+	return true, nil
+}
+
+// Date decodes the value of a date column
+func (c *ColumnValue) Date() (DateValue, bool, error) {
+	var value DateValue
+	ok, err := c.decode(&value)
+	if ok && value.Date == "" {
+		ok = false
+	}
+	return value, ok, err
+}
+
+// Number decodes the value of a numbers column, which is stored as a json string
+func (c *ColumnValue) Number() (float64, bool, error) {
+	var raw json.RawMessage
+	ok, err := c.decode(&raw)
+	if !ok || err != nil {
+		return 0, false, err
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		// a bare number works too
+		text = string(raw)
+	}
+	if text == "" {
+		return 0, false, nil
+	}
+
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("issue parsing the number '%s' of column %s: %w", text, c.ID, err)
+	}
+	return number, true, nil
+}
+
+// Timeline decodes the value of a timeline column
+func (c *ColumnValue) Timeline() (TimelineValue, bool, error) {
+	var value TimelineValue
+	ok, err := c.decode(&value)
+	if ok && (value.From == "" || value.To == "") {
+		ok = false
+	}
+	return value, ok, err
+}
+
+// Status decodes the value of a status column
+func (c *ColumnValue) Status() (StatusValue, bool, error) {
+	var value StatusValue
+	ok, err := c.decode(&value)
+	if !ok || err != nil {
+		return value, false, err
+	}
+	if value.Label == "" && c.Text != nil {
+		value.Label = *c.Text
+	}
+	return value, value.Index != nil, nil
+}
+
+// People decodes the value of a people column
+func (c *ColumnValue) People() (PeopleValue, bool, error) {
+	var value PeopleValue
+	ok, err := c.decode(&value)
+	return value, ok && len(value.PersonsAndTeams) > 0, err
+}
+
+// hoursDuration turns an estimate in hours into a duration
+func hoursDuration(hours float64) time.Duration {
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// itemEstimate returns the estimate of an item, or DefaultEstimateEventDuration when it has none
+func (b *Board) itemEstimate(task *Item) (time.Duration, error) {
+	hours, ok, err := b.itemValue(task, RoleEstimate).Number()
+	if err != nil || !ok {
+		return DefaultEstimateEventDuration, err
+	}
+	return hoursDuration(hours), nil
+}
+
+// itemDueDate returns the due date of an item in loc, or the zero time when it has none.
+// hasTime is false for due dates without a time, which are returned as midnight of their day.
+func (b *Board) itemDueDate(task *Item, loc *time.Location) (dueDate time.Time, hasTime bool, err error) {
+	value, ok, err := b.itemValue(task, RoleDueDate).Date()
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+	return value.In(loc)
+}
+
+// itemTimeline returns the first and last day of an item's timeline in loc, ok is false when it has none
+func (b *Board) itemTimeline(task *Item, loc *time.Location) (from time.Time, to time.Time, ok bool, err error) {
+	value, ok, err := b.itemValue(task, RoleTimeline).Timeline()
+	if err != nil || !ok {
+		return time.Time{}, time.Time{}, false, err
+	}
+	from, to, err = value.Days(loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	if to.Before(from) {
+		from, to = to, from
+	}
+	return from, to, true, nil
+}
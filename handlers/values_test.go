@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func columnValue(value string) *ColumnValue {
+	return &ColumnValue{ID: "column", Value: &value}
+}
+
+func TestDateValueIn(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		value    DateValue
+		want     string
+		wantTime bool
+		wantErr  bool
+	}{
+		{name: "date only is midnight in loc", value: DateValue{Date: "2026-10-21"}, want: "2026-10-21T00:00:00+02:00"},
+		{name: "time is utc", value: DateValue{Date: "2026-10-21", Time: "07:30:00"}, want: "2026-10-21T09:30:00+02:00", wantTime: true},
+		{name: "time moves to the next day", value: DateValue{Date: "2026-10-21", Time: "23:00:00"}, want: "2026-10-22T01:00:00+02:00", wantTime: true},
+		{name: "after daylight saving ends", value: DateValue{Date: "2026-10-26", Time: "08:00:00"}, want: "2026-10-26T09:00:00+01:00", wantTime: true},
+		{name: "bad date", value: DateValue{Date: "21/10/2026"}, wantErr: true},
+		{name: "bad time", value: DateValue{Date: "2026-10-21", Time: "9am"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hasTime, err := tt.value.In(berlin)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Format(time.RFC3339) != tt.want || hasTime != tt.wantTime {
+				t.Errorf("expected %s with time %t, got %s with time %t", tt.want, tt.wantTime, got.Format(time.RFC3339), hasTime)
+			}
+		})
+	}
+}
+
+func TestColumnValueNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		column  *ColumnValue
+		want    float64
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "json string", column: columnValue(`"1.5"`), want: 1.5, wantOK: true},
+		{name: "bare number", column: columnValue(`2`), want: 2, wantOK: true},
+		{name: "empty string", column: columnValue(`""`)},
+		{name: "null", column: columnValue(`null`)},
+		{name: "no value", column: &ColumnValue{ID: "column"}},
+		{name: "not a number", column: columnValue(`"two"`), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := tt.column.Number()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %t, got %v", tt.wantErr, err)
+			}
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("expected %v ok %t, got %v ok %t", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestColumnValueIsEmpty(t *testing.T) {
+	tests := []struct {
+		name   string
+		column *ColumnValue
+		want   bool
+	}{
+		{name: "nil column", column: nil, want: true},
+		{name: "no value", column: &ColumnValue{ID: "column"}, want: true},
+		{name: "empty", column: columnValue(``), want: true},
+		{name: "null", column: columnValue(`null`), want: true},
+		{name: "empty object", column: columnValue(`{}`), want: true},
+		{name: "empty string", column: columnValue(`""`), want: true},
+		{name: "date", column: columnValue(`{"date":"2026-10-21"}`), want: false},
+		{name: "number", column: columnValue(`"0"`), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.column.IsEmpty(); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestColumnValuePeople(t *testing.T) {
+	tests := []struct {
+		name   string
+		column *ColumnValue
+		want   int
+		wantOK bool
+	}{
+		{name: "person and team", column: columnValue(`{"personsAndTeams":[{"id":1,"kind":"person"},{"id":2,"kind":"team"}]}`), want: 2, wantOK: true},
+		{name: "nobody assigned", column: columnValue(`{"personsAndTeams":[]}`)},
+		{name: "no value", column: &ColumnValue{ID: "column"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := tt.column.People()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.PersonsAndTeams) != tt.want || ok != tt.wantOK {
+				t.Errorf("expected %d people ok %t, got %d ok %t", tt.want, tt.wantOK, len(got.PersonsAndTeams), ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// channels are renewed this long before google lets them expire
+	channelRenewBefore = time.Hour
+	channelCheckEvery  = time.Minute
+)
+
+// CalendarWatcher registers Events.Watch channels on board calendars and fetches
+// the events that changed, with the stored sync tokens, when google sends a notification
+type CalendarWatcher struct {
+	Calendar *CalendarClient
+	// Address is the public https url google sends notifications to
+	Address string
+	// OnChange is called with the events of a watched calendar that changed since the last read,
+	// also when a sync or a webhook made that read rather than a notification
+	OnChange func(cal *calendar.Calendar, events []*calendar.Event)
+
+	mu       sync.Mutex
+	channels map[string]*watchChannel
+}
+
+type watchChannel struct {
+	calendar   *calendar.Calendar
+	id         string
+	resourceID string
+	token      string
+	expiration time.Time
+}
+
+// NewCalendarWatcher makes a watcher that is told about every change read from calendarClient
+func NewCalendarWatcher(calendarClient *CalendarClient, address string, onChange func(cal *calendar.Calendar, events []*calendar.Event)) *CalendarWatcher {
+	w := &CalendarWatcher{
+		Calendar: calendarClient,
+		Address:  address,
+		OnChange: onChange,
+		channels: make(map[string]*watchChannel),
+	}
+	calendarClient.EventsChanged = w.eventsChanged
+	return w
+}
+
+// eventsChanged passes the changed events of a watched calendar to OnChange
+func (w *CalendarWatcher) eventsChanged(calendarID string, events []*calendar.Event) {
+	var cal *calendar.Calendar
+	w.mu.Lock()
+	for _, channel := range w.channels {
+		if channel.calendar.Id == calendarID {
+			cal = channel.calendar
+			break
+		}
+	}
+	w.mu.Unlock()
+
+	if cal != nil && w.OnChange != nil {
+		w.OnChange(cal, events)
+	}
+}
+
+// ManagedCalendars returns every calendar created for a board by CreateCalendarForBoardIfNotExist
+func (c *CalendarClient) ManagedCalendars() ([]*calendar.Calendar, error) {
+	var calendars []*calendar.Calendar
+	err := c.CalendarList.List().Pages(context.Background(), func(calendarList *calendar.CalendarList) error {
+		for _, calendarItem := range calendarList.Items {
+			// board calendars have the boardID as their description
+			if _, err := strconv.Atoi(calendarItem.Description); err != nil {
+				continue
+			}
+			calendars = append(calendars, &calendar.Calendar{
+				Id:          calendarItem.Id,
+				Description: calendarItem.Description,
+				Summary:     calendarItem.Summary,
+				TimeZone:    calendarItem.TimeZone,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve list of calendars: %w", googleError(err))
+	}
+
+	return calendars, nil
+}
+
+// Watch starts a notification channel for a calendar unless it is already watched
+func (w *CalendarWatcher) Watch(cal *calendar.Calendar) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, channel := range w.channels {
+		if channel.calendar.Id == cal.Id {
+			return nil
+		}
+	}
+
+	// read the calendar once so the first notification only fetches what changed after it
+	if _, err := w.Calendar.syncCalendarEvents(cal.Id); err != nil {
+		return err
+	}
+
+	return w.startChannel(cal)
+}
+
+// Run renews channels before they expire until ctx is done, then stops every channel
+func (w *CalendarWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(channelCheckEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.stopAll()
+			return
+		case <-ticker.C:
+			w.renewExpiring()
+		}
+	}
+}
+
+func (w *CalendarWatcher) renewExpiring() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for id, channel := range w.channels {
+		if time.Until(channel.expiration) > channelRenewBefore {
+			continue
+		}
+
+		if err := w.startChannel(channel.calendar); err != nil {
+			log.Printf("issue renewing watch channel of calendar '%s': %v", channel.calendar.Summary, err)
+			continue
+		}
+
+		w.stopChannel(channel)
+		delete(w.channels, id)
+	}
+}
+
+func (w *CalendarWatcher) stopAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for id, channel := range w.channels {
+		w.stopChannel(channel)
+		delete(w.channels, id)
+	}
+}
+
+func (w *CalendarWatcher) startChannel(cal *calendar.Calendar) error {
+	id, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	token, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+
+	channel, err := w.Calendar.Events.Watch(cal.Id, &calendar.Channel{
+		Id:      id,
+		Type:    "web_hook",
+		Address: w.Address,
+		Token:   token,
+	}).Do()
+	if err != nil {
+		return fmt.Errorf("issue watching calendar '%s': %w", cal.Summary, googleError(err))
+	}
+
+	w.channels[id] = &watchChannel{
+		calendar:   cal,
+		id:         id,
+		resourceID: channel.ResourceId,
+		token:      token,
+		expiration: time.Unix(0, channel.Expiration*int64(time.Millisecond)),
+	}
+	log.Printf("watching calendar '%s' until %s", cal.Summary, w.channels[id].expiration.Format(time.RFC3339))
+
+	return nil
+}
+
+func (w *CalendarWatcher) stopChannel(channel *watchChannel) {
+	err := w.Calendar.Channels.Stop(&calendar.Channel{
+		Id:         channel.id,
+		ResourceId: channel.resourceID,
+	}).Do()
+	if err != nil {
+		log.Printf("issue stopping watch channel of calendar '%s': %v", channel.calendar.Summary, err)
+	}
+}
+
+// ServeHTTP receives the push notifications google sends to Address
+func (w *CalendarWatcher) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	w.mu.Lock()
+	channel, ok := w.channels[req.Header.Get("X-Goog-Channel-ID")]
+	w.mu.Unlock()
+
+	// notifications for channels we no longer know about, or with the wrong token, are dropped
+	if !ok || req.Header.Get("X-Goog-Channel-Token") != channel.token {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// the first notification of a channel only confirms it was created
+	if req.Header.Get("X-Goog-Resource-State") == "sync" {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// the changed events reach OnChange through eventsChanged, after this answers google
+	if _, err := w.Calendar.syncCalendarEvents(channel.calendar.Id); err != nil {
+		log.Printf("issue getting changed events of calendar '%s': %v", channel.calendar.Summary, err)
+		http.Error(rw, "", http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("issue creating random id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// MondayWebhookHandler re-syncs single items when Monday.com reports a change through a webhook
+type MondayWebhookHandler struct {
+	Monday   *MondayClient
+	Calendar *CalendarClient
+	Options  SyncOptions
+	// Watcher, when set, is told about every board calendar the handler uses
+	Watcher *CalendarWatcher
+
+	// webhooks are handled one at a time so two changes to an item can't race each other
+	mu        sync.Mutex
+	calendars map[string]*calendar.Calendar
+}
+
+// MondayWebhook is the body Monday.com posts, either a challenge when the webhook is created or an event
+type MondayWebhook struct {
+	Challenge string              `json:"challenge,omitempty"`
+	Event     *MondayWebhookEvent `json:"event,omitempty"`
+}
+
+type MondayWebhookEvent struct {
+	Type    string      `json:"type"`
+	BoardID json.Number `json:"boardId"`
+	PulseID json.Number `json:"pulseId"`
+}
+
+// webhook event types, monday.com names items pulses in webhook payloads
+const (
+	WebhookCreateItem        = "create_pulse"
+	WebhookChangeColumnValue = "update_column_value"
+	WebhookChangeName        = "update_name"
+	WebhookItemDeleted       = "delete_pulse"
+	WebhookItemArchived      = "archive_pulse"
+	WebhookMoveItemToGroup   = "move_pulse_into_group"
+)
+
+func NewMondayWebhookHandler(mondayClient *MondayClient, calendarClient *CalendarClient, opts SyncOptions) *MondayWebhookHandler {
+	return &MondayWebhookHandler{
+		Monday:    mondayClient,
+		Calendar:  calendarClient,
+		Options:   opts,
+		calendars: make(map[string]*calendar.Calendar),
+	}
+}
+
+func (h *MondayWebhookHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(rw, "", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var webhook MondayWebhook
+	if err := json.NewDecoder(req.Body).Decode(&webhook); err != nil {
+		log.Printf("issue decoding monday.com webhook: %v", err)
+		http.Error(rw, "", http.StatusBadRequest)
+		return
+	}
+
+	// monday.com checks the url by posting a challenge that has to be sent back
+	if webhook.Challenge != "" {
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(MondayWebhook{Challenge: webhook.Challenge})
+		return
+	}
+
+	if webhook.Event == nil {
+		http.Error(rw, "", http.StatusBadRequest)
+		return
+	}
+
+	// a non 2xx response makes monday.com retry the webhook
+	if err := h.handleEvent(webhook.Event); err != nil {
+		log.Printf("issue handling monday.com '%s' webhook for item %s: %v", webhook.Event.Type, webhook.Event.PulseID, err)
+		http.Error(rw, "", http.StatusInternalServerError)
+	}
+}
+
+func (h *MondayWebhookHandler) handleEvent(event *MondayWebhookEvent) error {
+	switch event.Type {
+	case WebhookCreateItem, WebhookChangeColumnValue, WebhookChangeName, WebhookMoveItemToGroup:
+		return h.SyncItem(event.PulseID.String())
+	case WebhookItemDeleted, WebhookItemArchived:
+		return h.RemoveItem(event.BoardID.String(), event.PulseID.String())
+	}
+
+	log.Printf("ignoring monday.com '%s' webhook", event.Type)
+	return nil
+}
+
+// SyncItem re-syncs a single item to the calendar of its board
+func (h *MondayWebhookHandler) SyncItem(itemID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	board, err := h.Monday.GetItemBoard(itemID)
+	if err != nil {
+		return err
+	}
+
+	cal, err := h.boardCalendar(board)
+	if err != nil {
+		return err
+	}
+
+	plan, err := h.Calendar.PlanItemSync(board, itemID, cal, h.Options)
+	if err != nil {
+		return err
+	}
+
+	return h.apply(plan)
+}
+
+// RemoveItem deletes the events of an item that was deleted from a board
+func (h *MondayWebhookHandler) RemoveItem(boardID string, itemID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cal, ok := h.calendars[boardID]
+	if !ok {
+		var err error
+		cal, err = h.Calendar.FindCalendarForBoard(boardID)
+		if err != nil {
+			return err
+		}
+		if cal == nil {
+			return nil
+		}
+		h.calendars[boardID] = cal
+	}
+
+	plan, err := h.Calendar.PlanItemRemoval(&Board{ID: boardID}, itemID, cal)
+	if err != nil {
+		return err
+	}
+
+	return h.apply(plan)
+}
+
+func (h *MondayWebhookHandler) boardCalendar(board *Board) (*calendar.Calendar, error) {
+	if cal, ok := h.calendars[board.ID]; ok {
+		return cal, nil
+	}
+
+	cal, err := h.Calendar.CreateCalendarForBoardIfNotExist(board, h.Options.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	h.calendars[board.ID] = cal
+
+	if h.Watcher != nil {
+		if err := h.Watcher.Watch(cal); err != nil {
+			log.Printf("issue watching calendar '%s': %v", cal.Summary, err)
+		}
+	}
+
+	return cal, nil
+}
+
+func (h *MondayWebhookHandler) apply(plan *SyncPlan) error {
+	for _, change := range plan.Changes {
+		log.Printf("%s event '%s' on %s", change.Action, change.TaskName, change.Day.Format("Mon 2006-01-02"))
+	}
+
+	if err := h.Monday.ApplyItemFixes(plan); err != nil {
+		return err
+	}
+
+	// calendar edits are written to Monday.com before their events are recorded as synced
+	if err := h.Monday.ApplySyncPlan(plan); err != nil {
+		return err
+	}
+
+	if err := h.Calendar.ApplySyncPlan(plan); err != nil {
+		return fmt.Errorf("issue applying changes to calendar: %w", err)
+	}
+	return nil
+}
+
+// CalendarChanged handles events changed on a board calendar. With a two-way sync the items
+// of changed events are re-synced so the calendar edits are written back, otherwise they are reported.
+func (h *MondayWebhookHandler) CalendarChanged(cal *calendar.Calendar, events []*calendar.Event) {
+	for _, event := range events {
+		itemID := eventItemID(event)
+		if itemID == "" || !eventCreatedByTool(event) {
+			continue
+		}
+
+		// the tool's own writes come back as changes too, they still carry the ETag they were synced with
+		if itemState, ok := h.Calendar.State.Item(eventBoardID(event), itemID); ok && itemState.ETag == event.Etag {
+			continue
+		}
+
+		if event.Status == "cancelled" {
+			log.Printf("event '%s' of item %s was deleted from calendar '%s'", event.Summary, itemID, cal.Summary)
+			continue
+		}
+
+		if !h.Options.TwoWay {
+			log.Printf("event '%s' of item %s was changed on calendar '%s'", event.Summary, itemID, cal.Summary)
+			continue
+		}
+
+		if err := h.SyncItem(itemID); err != nil {
+			log.Printf("issue syncing calendar changes of '%s' back to monday.com: %v", event.Summary, err)
+		}
+	}
+}
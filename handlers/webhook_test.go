@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/machinebox/graphql"
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestCalendarChangedSkipsOwnWrites(t *testing.T) {
+	// every request to monday.com is an item being re-synced
+	var mondayRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mondayRequests++
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(`{"errors": [{"message": "not found"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	synced := testEvent("e1", "1", time.Date(2026, time.October, 21, 9, 0, 0, 0, time.UTC))
+	synced.ExtendedProperties.Private[BoardIDProperty] = "10"
+	edited := testEvent("e2", "2", time.Date(2026, time.October, 21, 10, 0, 0, 0, time.UTC))
+	edited.ExtendedProperties.Private[BoardIDProperty] = "10"
+
+	client := newTestCalendarClient(t)
+	client.State.SetItem("10", "1", ItemState{EventID: synced.Id, ETag: synced.Etag})
+	client.State.SetItem("10", "2", ItemState{EventID: edited.Id, ETag: "etag-before-the-edit"})
+
+	handler := &MondayWebhookHandler{
+		Monday:   &MondayClient{Client: graphql.NewClient(server.URL)},
+		Calendar: client,
+		Options:  SyncOptions{TwoWay: true},
+	}
+	handler.CalendarChanged(&calendar.Calendar{Id: testCalendarID, Summary: "board"}, []*calendar.Event{synced, edited})
+
+	if mondayRequests != 1 {
+		t.Errorf("expected only the edited event's item to be re-synced, got %d monday.com requests", mondayRequests)
+	}
+}
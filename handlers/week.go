@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	WeekOf Title = "Week Of"
+
+	WeekOfDateFormat string = "2006-01-02"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// date formats a group can be titled with
+var (
+	groupDateFormats = []string{
+		"2006-01-02",
+		"Mon Jan 2 2006",
+		"Mon Jan 2, 2006",
+		"Mon, Jan 2, 2006",
+		"Monday, January 2, 2006",
+		"Jan 2 2006",
+		"Jan 2, 2006",
+		"January 2 2006",
+		"January 2, 2006",
+	}
+	groupDateWithoutYearFormats = []string{
+		"Mon Jan 2",
+		"Mon, Jan 2",
+		"Monday Jan 2",
+		"Monday, Jan 2",
+		"Monday January 2",
+		"Monday, January 2",
+		"Jan 2",
+		"January 2",
+	}
+)
+
+var (
+	isoWeekRegexp = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+
+	// dates a board name may carry, e.g. "Team Schedule - Week of 2026-10-19"
+	boardNameDateRegexp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+	boardNameWeekRegexp = regexp.MustCompile(`\d{4}-W\d{2}`)
+)
+
+// ParseISOWeek turns an ISO 8601 week like 2026-W43 into the Monday that starts it
+func ParseISOWeek(value string) (time.Time, error) {
+	matches := isoWeekRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return time.Time{}, fmt.Errorf("'%s' is not an ISO week, use the format 2026-W43", value)
+	}
+
+	year, _ := strconv.Atoi(matches[1])
+	week, _ := strconv.Atoi(matches[2])
+
+	// January 4th is always in the first ISO week of the year
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	isoWeekday := (int(jan4.Weekday())+6)%7 + 1
+	monday := jan4.AddDate(0, 0, (week-1)*7-(isoWeekday-1))
+
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, fmt.Errorf("'%s' is not a week of %d", value, year)
+	}
+
+	return monday, nil
+}
+
+// weekStart returns midnight of the Sunday that starts the week of t, in loc
+func weekStart(t time.Time, loc *time.Location) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// boardWeekOf finds the week a board is for, first from a "Week Of" date column
+// on its items and then from a date or ISO week in the board name
+func boardWeekOf(board *Board) (time.Time, bool) {
+	for _, group := range board.Groups {
+		for _, task := range group.Items {
+			for _, columnValue := range task.ColumnValues {
+				if columnValue.Title != WeekOf {
+					continue
+				}
+
+				value, ok, err := columnValue.Date()
+				if err != nil || !ok {
+					continue
+				}
+				if weekOf, err := time.Parse(WeekOfDateFormat, value.Date); err == nil {
+					return weekOf, true
+				}
+			}
+		}
+	}
+
+	if match := boardNameWeekRegexp.FindString(board.Name); match != "" {
+		if weekOf, err := ParseISOWeek(match); err == nil {
+			return weekOf, true
+		}
+	}
+
+	if match := boardNameDateRegexp.FindString(board.Name); match != "" {
+		if weekOf, err := time.Parse(WeekOfDateFormat, match); err == nil {
+			return weekOf, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// groupDay returns midnight of the day a group is for. Weekday titles are placed in the
+// week starting at weekStart and dates without a year get the year closest to weekStart.
+func groupDay(title string, weekStart time.Time, loc *time.Location) (time.Time, bool) {
+	title = strings.TrimSpace(title)
+
+	if weekday, ok := weekdays[strings.ToLower(title)]; ok {
+		return weekStart.AddDate(0, 0, int(weekday)), true
+	}
+
+	for _, format := range groupDateFormats {
+		if date, err := time.Parse(format, title); err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc), true
+		}
+	}
+
+	for _, format := range groupDateWithoutYearFormats {
+		date, err := time.Parse(format, title)
+		if err != nil {
+			continue
+		}
+
+		var closest time.Time
+		for _, year := range []int{weekStart.Year() - 1, weekStart.Year(), weekStart.Year() + 1} {
+			candidate := time.Date(year, date.Month(), date.Day(), 0, 0, 0, 0, loc)
+			if closest.IsZero() || absDuration(candidate.Sub(weekStart)) < absDuration(closest.Sub(weekStart)) {
+				closest = candidate
+			}
+		}
+		return closest, true
+	}
+
+	return time.Time{}, false
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// dayKey identifies a calendar day, independent of the time of day
+func dayKey(day time.Time) string {
+	return day.Format(WeekOfDateFormat)
+}
+
+// eventDayKey is the dayKey of the day an event starts on, in loc
+func eventDayKey(event *calendar.Event, loc *time.Location) string {
+	return eventTimeDayKey(event.Start, loc)
+}
+
+// eventOnDay reports whether an event starts or ends on day
+func eventOnDay(event *calendar.Event, day time.Time, loc *time.Location) bool {
+	return eventTimeDayKey(event.Start, loc) == dayKey(day) || eventTimeDayKey(event.End, loc) == dayKey(day)
+}
+
+func eventTimeDayKey(eventTime *calendar.EventDateTime, loc *time.Location) string {
+	if eventTime == nil {
+		return ""
+	}
+	if eventTime.DateTime == "" {
+		return eventTime.Date
+	}
+
+	t, err := time.Parse(time.RFC3339, eventTime.DateTime)
+	if err != nil {
+		return ""
+	}
+	return dayKey(t.In(loc))
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseISOWeek(t *testing.T) {
+	tests := []struct {
+		week    string
+		want    string
+		wantErr bool
+	}{
+		{week: "2026-W43", want: "2026-10-19"},
+		{week: "2026-W01", want: "2025-12-29"},
+		{week: "2026-W53", want: "2026-12-28"},
+		{week: "2020-W53", want: "2020-12-28"},
+		{week: "2025-W53", wantErr: true},
+		{week: "2026-W00", wantErr: true},
+		{week: "2026-W54", wantErr: true},
+		{week: "2026-43", wantErr: true},
+		{week: "2026-W4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.week, func(t *testing.T) {
+			monday, err := ParseISOWeek(tt.week)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %s", dayKey(monday))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dayKey(monday) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, dayKey(monday))
+			}
+		})
+	}
+}
+
+func TestGroupDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		title     string
+		weekStart time.Time
+		want      string
+		wantOK    bool
+	}{
+		{name: "weekday", title: "Friday", weekStart: date(berlin, 2026, time.October, 18), want: "2026-10-23", wantOK: true},
+		{name: "weekday any case", title: " sunday ", weekStart: date(berlin, 2026, time.October, 18), want: "2026-10-18", wantOK: true},
+		{name: "iso date", title: "2026-10-21", weekStart: date(berlin, 2026, time.October, 18), want: "2026-10-21", wantOK: true},
+		{name: "date with year", title: "Mon, Dec 28, 2026", weekStart: date(berlin, 2026, time.December, 27), want: "2026-12-28", wantOK: true},
+		{name: "without year in the same year", title: "Wednesday, October 21", weekStart: date(berlin, 2026, time.October, 18), want: "2026-10-21", wantOK: true},
+		{name: "without year after new year", title: "Jan 2", weekStart: date(berlin, 2026, time.December, 27), want: "2027-01-02", wantOK: true},
+		{name: "without year before new year", title: "Dec 31", weekStart: date(berlin, 2027, time.January, 3), want: "2026-12-31", wantOK: true},
+		{name: "not a day", title: "Backlog", weekStart: date(berlin, 2026, time.October, 18)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, ok := groupDay(tt.title, tt.weekStart, berlin)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok %t, got %t", tt.wantOK, ok)
+			}
+			if !ok {
+				return
+			}
+			if dayKey(day) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, dayKey(day))
+			}
+			if day.Location() != berlin || day.Hour() != 0 {
+				t.Errorf("expected midnight in %s, got %s", berlin, day)
+			}
+		})
+	}
+}
+
+// date is midnight of a day in loc, the week starts groupDay is given are in the sync time zone
+func date(loc *time.Location, year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"os"
+
 	"github.com/sebradloff/monday-gcal-integration/cmd"
 )
 
 func main() {
-	cmd.Execute()
+	err := cmd.Execute()
+	os.Exit(cmd.ExitCode(err))
 }